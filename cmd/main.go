@@ -2,13 +2,20 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 
 	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
 	"github.com/brenank/json-schema-to-go-struct-generator/pkg/utils"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(lint(os.Args[2:]))
+	}
+
 	flags := utils.ParseFlags() // Parsing the cl flags
 	files, err := utils.ReadFiles(flags.InputDir)
 	if err != nil {
@@ -25,10 +32,134 @@ func main() {
 		panic(err)
 	}
 
+	tags, err := inputs.ParseTagOptions(flags.Tags)
+	if err != nil {
+		panic(err)
+	}
+
+	layout, err := inputs.ParseLayout(flags.Layout)
+	if err != nil {
+		panic(err)
+	}
+
+	generatorOptions, err := newGeneratorOptions(flags.GeneratorFlags)
+	if err != nil {
+		panic(err)
+	}
+
 	fmt.Printf("Processing files: %v\n", files)
-	err = converter.Convert(files, packageName, outPath, false)
+	err = converter.ConvertWithOptions(files, outPath, converter.Options{
+		PackageName: packageName,
+		Generator:   generatorOptions,
+		Output: inputs.OutputOptions{
+			AlphabeticalFields: flags.AlphabeticalFields,
+			Tags:               tags,
+			ValidateTags:       flags.ValidateTags,
+			Layout:             layout,
+			TemplateDir:        flags.TemplateDir,
+			Runtime:            flags.Runtime,
+		},
+		TypeCheck: flags.TypeCheck,
+	})
 
 	if err != nil {
 		panic(err)
 	}
 }
+
+// lint reports the problems of the schemas, returning the exit status: 1 when there are errors, or warnings with
+// -strict.
+func lint(args []string) int {
+	flags := utils.ParseLintFlags(args)
+	files, err := utils.ReadFiles(flags.InputDir)
+	if err != nil {
+		panic(err)
+	}
+	// findings are reported against paths relative to the working directory, as a PR check shows them
+	if wd, err := os.Getwd(); err == nil {
+		for i, file := range files {
+			if rel, err := filepath.Rel(wd, file); err == nil {
+				files[i] = rel
+			}
+		}
+	}
+
+	generatorOptions, err := newGeneratorOptions(flags.GeneratorFlags)
+	if err != nil {
+		panic(err)
+	}
+	findings, err := inputs.LintFiles(files, generatorOptions)
+	if err != nil {
+		panic(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if flags.Output != "" {
+		f, err := os.Create(flags.Output)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch flags.Format {
+	case "text":
+		err = inputs.WriteLintText(w, findings)
+	case "sarif":
+		err = inputs.WriteSARIF(w, findings)
+	default:
+		err = fmt.Errorf("unknown lint format %q, expected text or sarif", flags.Format)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	for _, f := range findings {
+		if f.Level == inputs.LevelError || flags.Strict {
+			return 1
+		}
+	}
+	return 0
+}
+
+// newGeneratorOptions creates the options of the generator from the flags shared by generation and lint.
+func newGeneratorOptions(flags utils.GeneratorFlags) (inputs.GeneratorOptions, error) {
+	typeMap, err := inputs.ParseTypeMap(flags.TypeMap)
+	if err != nil {
+		return inputs.GeneratorOptions{}, err
+	}
+
+	packages, err := inputs.ParsePackageMappings(flags.Packages)
+	if err != nil {
+		return inputs.GeneratorOptions{}, err
+	}
+
+	refMap, err := inputs.ParseRefMap(flags.RefMap)
+	if err != nil {
+		return inputs.GeneratorOptions{}, err
+	}
+
+	// remote documents are only fetched when asked to, so builds don't depend on the network by default
+	var loaders []inputs.Loader
+	if len(refMap) > 0 {
+		loaders = append(loaders, &inputs.PrefixLoader{Prefixes: refMap})
+	}
+	var fetcher inputs.Loader
+	if flags.RefFetch {
+		fetcher = &inputs.HTTPLoader{}
+	}
+	if flags.RefCache != "" {
+		loaders = append(loaders, &inputs.CacheLoader{Dir: flags.RefCache, Next: fetcher})
+	} else if fetcher != nil {
+		loaders = append(loaders, fetcher)
+	}
+
+	return inputs.GeneratorOptions{
+		TypeMap:        typeMap,
+		Packages:       packages,
+		SearchPaths:    inputs.ParseSearchPaths(flags.RefDirs),
+		Loaders:        loaders,
+		OpenAPIBodies:  flags.OpenAPIBodies,
+		SkipMetaSchema: flags.SkipMetaSchema,
+	}, nil
+}
@@ -1,7 +1,9 @@
 package converter
 
 import (
-	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,7 +12,32 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Options configures a conversion.
+type Options struct {
+	// PackageName is the name of the generated go package.
+	PackageName string
+	// Generator controls how types are generated.
+	Generator inputs.GeneratorOptions
+	// Output controls how the generated code is written.
+	Output inputs.OutputOptions
+	// OutputDir is the directory of the default package. Imports are resolved from it when type-checking.
+	OutputDir string
+	// TypeCheck type-checks the generated code before it is written, failing on compile errors.
+	TypeCheck bool
+}
+
 func Convert(inputFiles []string, packageName string, outputFile string, debug bool) error {
+	return ConvertWithOptions(inputFiles, outputFile, Options{
+		PackageName: packageName,
+		Output:      inputs.OutputOptions{Debug: debug},
+	})
+}
+
+// ConvertWithOptions generates go code for the input files using the given options. Types placed in mapped
+// packages are written to a file with the same name as outputFile in the directory of their package. When
+// opts.Output.Layout splits the code into several files, they are written to the directory of outputFile, or of
+// the mapped package, instead. Nothing is written when generation fails.
+func ConvertWithOptions(inputFiles []string, outputFile string, opts Options) error {
 	//ensure that files are aways processed in deterministic order
 	sort.Strings(inputFiles)
 
@@ -19,23 +46,149 @@ func Convert(inputFiles []string, packageName string, outputFile string, debug b
 		return errors.Wrapf(err, "error while reading input file")
 
 	}
-	generatorInstance := inputs.New(schemas...) // instance of generator which will produce structs
-	err = generatorInstance.CreateTypes()
+
+	// the default package is written next to outputFile, mapped packages to their own directory
+	opts.OutputDir = filepath.Dir(outputFile)
+	opts.Output.FileName = filepath.Base(outputFile)
+	pkgs, err := generate(schemas, inputFiles, opts)
+	if err != nil {
+		return err
+	}
+	return WritePackages(pkgs)
+}
+
+// Generate generates go code for schema documents held in memory without touching the filesystem. The default
+// package is placed in opts.OutputDir and its single file, if not split by opts.Output.Layout, is named by
+// opts.Output.FileName. Mapped packages are placed in their own directory.
+func Generate(sources []inputs.Source, opts Options) ([]inputs.GeneratedPackage, error) {
+	schemas, err := inputs.ReadSources(sources, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while reading input schema")
+	}
+
+	paths := make([]string, len(sources))
+	for i, source := range sources {
+		paths[i] = source.URI
+		if u, err := url.Parse(source.URI); err == nil && u.Scheme == "file" {
+			paths[i] = u.Path
+		}
+	}
+	return generate(schemas, paths, opts)
+}
+
+// GenerateFS generates go code for the schema files at the given paths of fsys, e.g. an embed.FS, without touching
+// the filesystem. Relative references between the files resolve within fsys, and referenced files which are not
+// inputs are loaded from it. The output is placed as by Generate.
+func GenerateFS(fsys fs.FS, inputFiles []string, opts Options) ([]inputs.GeneratedPackage, error) {
+	if opts.Generator.FS == nil {
+		opts.Generator.FS = fsys
+	}
+
+	//ensure that files are aways processed in deterministic order
+	inputFiles = append([]string(nil), inputFiles...)
+	sort.Strings(inputFiles)
+
+	schemas, err := inputs.ReadInputFS(fsys, inputFiles, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while reading input file")
+	}
+	return generate(schemas, inputFiles, opts)
+}
+
+// ConvertFS generates go code for the schema files at the given paths of fsys and writes it like ConvertWithOptions.
+func ConvertFS(fsys fs.FS, inputFiles []string, outputFile string, opts Options) error {
+	opts.OutputDir = filepath.Dir(outputFile)
+	opts.Output.FileName = filepath.Base(outputFile)
+	pkgs, err := GenerateFS(fsys, inputFiles, opts)
+	if err != nil {
+		return err
+	}
+	return WritePackages(pkgs)
+}
+
+// GenerateSource generates the go source of schema documents held in memory, which must produce a single file.
+func GenerateSource(sources []inputs.Source, opts Options) ([]byte, error) {
+	pkgs, err := Generate(sources, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 || len(pkgs[0].Files) != 1 {
+		return nil, errors.New("the generated code spans several files, use Generate instead")
+	}
+	return pkgs[0].Files[0].Content, nil
+}
+
+func generate(schemas []*inputs.Schema, originatingPaths []string, opts Options) ([]inputs.GeneratedPackage, error) {
+	generatorInstance := inputs.NewWithOptions(opts.Generator, schemas...) // instance of generator which will produce structs
+	err := generatorInstance.CreateTypes()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while generating instance for producing structs")
+	}
+
+	files, err := inputs.OutputFiles(generatorInstance, nil, opts.PackageName, originatingPaths, opts.Output)
 	if err != nil {
-		return errors.Wrapf(err, "error while generating instance for producing structs")
+		return nil, err
 	}
+	pkgs := []inputs.GeneratedPackage{{Name: opts.PackageName, Dir: opts.OutputDir, Files: files}}
 
+	for _, mapping := range generatorInstance.Packages() {
+		if mapping.Dir == "" {
+			return nil, errors.Errorf("no output directory for package %s", mapping.ImportPath)
+		}
+		files, err := inputs.OutputFiles(generatorInstance, mapping, mapping.PackageName(), originatingPaths, opts.Output)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, inputs.GeneratedPackage{Mapping: mapping, Name: mapping.PackageName(), Dir: mapping.Dir, Files: files})
+	}
+
+	if opts.TypeCheck {
+		if err := inputs.TypeCheck(generatorInstance, pkgs); err != nil {
+			return nil, err
+		}
+	}
+	return pkgs, nil
+}
+
+// WritePackages writes the files of generated packages to their directories. Each file is written to a temporary
+// file which replaces the destination once complete, so a failure never leaves a truncated file behind.
+func WritePackages(pkgs []inputs.GeneratedPackage) error {
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			if err := writeFileAtomic(filepath.Join(pkg.Dir, file.Name), file.Content); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeFileAtomic(outputFile string, content []byte) error {
 	packageDirectory := filepath.Dir(outputFile)
-	err = os.MkdirAll(packageDirectory, 0755)
+	err := os.MkdirAll(packageDirectory, 0755)
 	if err != nil {
 		return errors.Wrapf(err, "error while creating directory")
 	}
 
-	var w io.Writer
-	w, err = os.Create(outputFile)
+	f, err := ioutil.TempFile(packageDirectory, "."+filepath.Base(outputFile)+".*.tmp")
 	if err != nil {
 		return errors.Wrapf(err, "error while creating output file")
 	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // a no-op once renamed
 
-	return inputs.Output(w, generatorInstance, packageName, inputFiles, debug)
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "error while writing output file")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "error while writing output file")
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		return errors.Wrapf(err, "error while writing output file")
+	}
+	if err := os.Rename(tmp, outputFile); err != nil {
+		return errors.Wrapf(err, "error while replacing output file")
+	}
+	return nil
 }
@@ -0,0 +1,178 @@
+package inputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Message is the payload schema of a message of an AsyncAPI document.
+type Message struct {
+	// Name of the message, which the message registry maps onto its type, e.g. "userSignedUp".
+	Name string
+	// TypeName is the name of the type generated for the payload, e.g. "UserSignedUp".
+	TypeName string
+	// Pointer to the payload in the AsyncAPI document, e.g. ["components", "messages", "UserSignedUp", "payload"].
+	Pointer Pointer
+	// Schema of the payload.
+	Schema *Schema
+}
+
+type asyncAPIDocument struct {
+	AsyncAPI string `json:"asyncapi"`
+	Info     struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Channels map[string]struct {
+		Subscribe *asyncAPIOperation `json:"subscribe"`
+		Publish   *asyncAPIOperation `json:"publish"`
+	} `json:"channels"`
+	Components struct {
+		Schemas  map[string]*Schema          `json:"schemas"`
+		Messages map[string]*asyncAPIMessage `json:"messages"`
+	} `json:"components"`
+}
+
+type asyncAPIOperation struct {
+	OperationID string           `json:"operationId"`
+	Message     *asyncAPIMessage `json:"message"`
+}
+
+// asyncAPIMessage is a message, a reference to one in the components of the document, or a choice of messages.
+type asyncAPIMessage struct {
+	Reference    string             `json:"$ref"`
+	MessageID    string             `json:"messageId"`
+	Name         string             `json:"name"`
+	SchemaFormat string             `json:"schemaFormat"`
+	Payload      *Schema            `json:"payload"`
+	OneOf        []*asyncAPIMessage `json:"oneOf"`
+}
+
+// parseAsyncAPI converts the AsyncAPI 2.x document b, identified by uri, to a schema holding its component schemas
+// and the payloads of its messages, those of the components followed by those defined in its channels.
+func parseAsyncAPI(b []byte, uri *url.URL) (*Schema, error) {
+	doc := asyncAPIDocument{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, absoluteOffset(err, b)
+	}
+	if !strings.HasPrefix(doc.AsyncAPI, "2.") {
+		return nil, fmt.Errorf("unsupported AsyncAPI version \"%s\", only 2.x is supported", doc.AsyncAPI)
+	}
+
+	messages, err := doc.messages()
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{
+		ID06:        uri.String(),
+		SourceURI:   uri.String(),
+		Title:       doc.Info.Title,
+		Description: doc.Info.Description,
+		AsyncAPI:    doc.AsyncAPI,
+		Components:  doc.Components.Schemas,
+		Messages:    messages,
+	}
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// messages returns the messages with a payload, named by their name, messageId or component key, or by the
+// operation or channel they are defined in.
+func (doc *asyncAPIDocument) messages() ([]Message, error) {
+	var messages []Message
+	seen := map[string]Pointer{}
+	var add func(name string, pointer Pointer, m *asyncAPIMessage) error
+	add = func(name string, pointer Pointer, m *asyncAPIMessage) error {
+		if m == nil {
+			return nil
+		}
+		if m.Reference != "" {
+			if strings.HasPrefix(m.Reference, "#/components/messages/") {
+				// added once under its own name
+				return nil
+			}
+			return fmt.Errorf("message reference \"%s\" at \"%s\" is not supported, only #/components/messages are", m.Reference, pointer)
+		}
+		for i, one := range m.OneOf {
+			if err := add(name+strconv.Itoa(i+1), pointer.Append("oneOf", strconv.Itoa(i)), one); err != nil {
+				return err
+			}
+		}
+		if m.Payload == nil {
+			return nil
+		}
+		if !isJSONSchemaFormat(m.SchemaFormat) {
+			return fmt.Errorf("the payload of the message at \"%s\" has the unsupported schemaFormat \"%s\"", pointer, m.SchemaFormat)
+		}
+		if m.MessageID != "" {
+			name = m.MessageID
+		}
+		if m.Name != "" {
+			name = m.Name
+		}
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("the message name \"%s\" at \"%s\" is already used at \"%s\"", name, pointer, other)
+		}
+		seen[name] = pointer
+		messages = append(messages, Message{
+			Name:     name,
+			TypeName: GetGolangName(name),
+			Pointer:  pointer.Append("payload"),
+			Schema:   m.Payload,
+		})
+		return nil
+	}
+
+	keys := make([]string, 0, len(doc.Components.Messages))
+	for k := range doc.Components.Messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := add(k, Pointer{"components", "messages", k}, doc.Components.Messages[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	channels := make([]string, 0, len(doc.Channels))
+	for channel := range doc.Channels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		for _, op := range []struct {
+			name      string
+			operation *asyncAPIOperation
+		}{{"subscribe", doc.Channels[channel].Subscribe}, {"publish", doc.Channels[channel].Publish}} {
+			if op.operation == nil {
+				continue
+			}
+			name := op.operation.OperationID
+			if name == "" {
+				name = channel + " " + op.name
+			}
+			if err := add(name, Pointer{"channels", channel, op.name, "message"}, op.operation.Message); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return messages, nil
+}
+
+// isJSONSchemaFormat reports whether a message payload with the schemaFormat is a JSON schema: the AsyncAPI schema,
+// which is the default, or JSON schema in JSON or YAML.
+func isJSONSchemaFormat(schemaFormat string) bool {
+	mediaType := strings.TrimSpace(strings.SplitN(schemaFormat, ";", 2)[0])
+	switch mediaType {
+	case "", "application/vnd.aai.asyncapi", "application/vnd.aai.asyncapi+json", "application/vnd.aai.asyncapi+yaml",
+		"application/schema+json", "application/schema+yaml":
+		return true
+	}
+	return false
+}
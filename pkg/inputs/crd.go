@@ -0,0 +1,180 @@
+package inputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// The packages of the Kubernetes types used by the types generated from a CustomResourceDefinition.
+const (
+	metav1Import  = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	intstrImport  = "k8s.io/apimachinery/pkg/util/intstr"
+	runtimeImport = "k8s.io/apimachinery/pkg/runtime"
+)
+
+// CustomResource is the schema of a version of a Kubernetes custom resource.
+type CustomResource struct {
+	// Name of the type generated for the version, the kind of the resource, e.g. "CronTab", which is followed by
+	// the version when the definition has several, e.g. "CronTabV1beta1".
+	Name string
+	// Group, Version and Kind of the resource, e.g. "stable.example.com", "v1" and "CronTab".
+	Group   string
+	Version string
+	Kind    string
+	// Pointer to the schema in the CustomResourceDefinition, e.g.
+	// ["spec", "versions", "0", "schema", "openAPIV3Schema"].
+	Pointer Pointer
+	// Schema of the version.
+	Schema *Schema
+}
+
+type crdDocument struct {
+	APIVersion string `json:"apiVersion"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Versions []struct {
+			Name   string `json:"name"`
+			Schema struct {
+				OpenAPIV3Schema *Schema `json:"openAPIV3Schema"`
+			} `json:"schema"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// parseCRD converts the apiextensions.k8s.io/v1 CustomResourceDefinition b, identified by uri, to a schema holding
+// the schemas of the versions of its resource. Each version gets the apiVersion, kind and metadata of a Kubernetes
+// object, and the x-kubernetes extensions are translated to the Kubernetes types implementing them.
+func parseCRD(b []byte, uri *url.URL) (*Schema, error) {
+	doc := crdDocument{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, absoluteOffset(err, b)
+	}
+	if doc.APIVersion != "apiextensions.k8s.io/v1" {
+		return nil, fmt.Errorf("unsupported CustomResourceDefinition version \"%s\", only apiextensions.k8s.io/v1 is supported", doc.APIVersion)
+	}
+	if doc.Spec.Names.Kind == "" {
+		return nil, fmt.Errorf("the CustomResourceDefinition %s has no spec.names.kind", doc.Metadata.Name)
+	}
+
+	var resources []CustomResource
+	for i, v := range doc.Spec.Versions {
+		if v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		resources = append(resources, CustomResource{
+			Group:   doc.Spec.Group,
+			Version: v.Name,
+			Kind:    doc.Spec.Names.Kind,
+			Pointer: Pointer{"spec", "versions", strconv.Itoa(i), "schema", "openAPIV3Schema"},
+			Schema:  v.Schema.OpenAPIV3Schema,
+		})
+	}
+	for i := range resources {
+		resources[i].Name = GetGolangName(resources[i].Kind)
+		if len(resources) > 1 {
+			resources[i].Name += GetGolangName(resources[i].Version)
+		}
+		kubernetesObject(resources[i].Schema)
+	}
+
+	s := &Schema{
+		ID06:            uri.String(),
+		SourceURI:       uri.String(),
+		Title:           doc.Metadata.Name,
+		CRD:             doc.Metadata.Name,
+		CustomResources: resources,
+	}
+	s.Walk(func(_ string, schema *Schema) error {
+		kubernetesExtensions(schema)
+		return nil
+	})
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// kubernetesObject replaces the apiVersion, kind and metadata properties of the schema of a Kubernetes object with
+// the embedded TypeMeta and the ObjectMeta of the Kubernetes API machinery.
+func kubernetesObject(schema *Schema) {
+	if schema.TypeValue == nil {
+		schema.TypeValue = "object"
+	}
+	metadata := schema.Properties["metadata"]
+	if metadata == nil {
+		metadata = &Schema{}
+	}
+	metadata.TypeValue = nil
+	metadata.Properties = nil
+	metadata.PropertyOrder = nil
+	metadata.GoType = "v1.ObjectMeta"
+	metadata.GoTypeImport = metav1Import
+
+	properties := map[string]*Schema{
+		"TypeMeta": {
+			GoType:       "v1.TypeMeta",
+			GoTypeImport: metav1Import,
+			GoEmbed:      true,
+			GoTags:       map[string]string{"json": ",inline"},
+			GoEmbedNames: []string{"apiVersion", "kind"},
+		},
+		"metadata": metadata,
+	}
+	order := []string{"TypeMeta", "metadata"}
+	for _, k := range schema.OrderedPropertyNames() {
+		switch k {
+		case "apiVersion", "kind", "metadata":
+			continue
+		}
+		properties[k] = schema.Properties[k]
+		order = append(order, k)
+	}
+	schema.Properties = properties
+	schema.PropertyOrder = order
+
+	// the type meta is set by the client and the metadata may be empty
+	var required []string
+	for _, k := range schema.Required {
+		switch k {
+		case "apiVersion", "kind", "metadata":
+			continue
+		}
+		required = append(required, k)
+	}
+	schema.Required = required
+}
+
+// kubernetesExtensions translates the x-kubernetes extensions of schema: an int-or-string becomes an
+// intstr.IntOrString, an embedded resource a Kubernetes object, or a runtime.RawExtension when all of its fields are
+// preserved, and the unknown fields of an object are preserved as additional properties.
+func kubernetesExtensions(schema *Schema) {
+	if schema.GoType != "" {
+		return
+	}
+	if schema.IntOrString {
+		schema.GoType = "intstr.IntOrString"
+		schema.GoTypeImport = intstrImport
+		return
+	}
+	if schema.EmbeddedResource {
+		if schema.PreserveUnknownFields && len(schema.Properties) == 0 {
+			schema.GoType = "runtime.RawExtension"
+			schema.GoTypeImport = runtimeImport
+			return
+		}
+		kubernetesObject(schema)
+	}
+	if schema.PreserveUnknownFields && schema.AdditionalProperties == nil {
+		if t, _ := schema.Type(); t == "object" || len(schema.Properties) > 0 {
+			schema.AdditionalProperties = &AdditionalProperties{}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package inputs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"io"
+	"sort"
+	"strings"
+)
+
+// sourceSpan marks where the generated code of a declaration starts.
+type sourceSpan struct {
+	offset int
+	// what the code declares, e.g. "type Address" or "the methods of Address"
+	what string
+}
+
+// formatSource runs generated code through go/format. Syntax errors are reported against the declaration
+// containing them rather than writing broken code.
+func formatSource(src []byte, spans []sourceSpan) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err == nil {
+		return formatted, nil
+	}
+
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return nil, fmt.Errorf("generated code could not be formatted: %v", err)
+	}
+
+	first := list[0]
+	what := "the package clause or imports"
+	for _, span := range spans {
+		if span.offset <= first.Pos.Offset {
+			what = span.what
+		}
+	}
+	return nil, fmt.Errorf("generated code for %s does not parse: %s at line %d: %s", what, first.Msg, first.Pos.Line, strings.TrimSpace(sourceLine(src, first.Pos.Line)))
+}
+
+// sourceLine returns the 1-based line n of src.
+func sourceLine(src []byte, n int) string {
+	lines := bytes.Split(src, []byte("\n"))
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return string(lines[n-1])
+}
+
+// writeImports writes an import block with the standard library imports first, followed by the other imports,
+// each group sorted.
+func writeImports(w io.Writer, imports map[string]bool) {
+	if len(imports) == 0 {
+		return
+	}
+
+	var std, other []string
+	for k := range imports {
+		if isStandardImport(k) {
+			std = append(std, k)
+		} else {
+			other = append(other, k)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	fmt.Fprintf(w, "\nimport (\n")
+	for _, k := range std {
+		fmt.Fprintf(w, "\t%q\n", k)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, k := range other {
+		fmt.Fprintf(w, "\t%q\n", k)
+	}
+	fmt.Fprintf(w, ")\n")
+}
+
+// isStandardImport reports whether path belongs to the standard library, whose first path element has no dot.
+func isStandardImport(path string) bool {
+	first := path
+	if idx := strings.Index(path, "/"); idx >= 0 {
+		first = path[:idx]
+	}
+	return !strings.Contains(first, ".")
+}
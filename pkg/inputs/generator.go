@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"regexp"
 	"sort"
 	"strings"
@@ -12,64 +14,174 @@ import (
 	"github.com/brenank/json-schema-to-go-struct-generator/pkg/utils"
 )
 
+// GeneratorOptions configure how types are generated.
+type GeneratorOptions struct {
+	// TypeMap binds schema URIs or JSON pointers, e.g. "common.json#/definitions/money", to existing go types, e.g.
+	// "github.com/acme/money.Amount", which are used instead of generating a type. Keys which are not absolute URIs
+	// match any schema URI ending with them.
+	TypeMap map[string]string
+	// Packages place the types generated from matching schemas into their own go packages. Types from schemas
+	// which don't match any package are generated into the default package.
+	Packages []PackageMapping
+	// SearchPaths are directories searched for documents referenced by a $ref which are neither inputs nor found
+	// relative to the referring document. Relative references are looked up by their path, absolute references by
+	// their file name.
+	SearchPaths []string
+	// FS, if set, is the file system referenced documents and search paths are read from instead of the disk.
+	FS fs.FS
+	// Loaders load the referenced documents which are not found as files, e.g. those with a https URI. They are
+	// tried in order.
+	Loaders []Loader
+	// OpenAPIBodies generates types for the request and response bodies of the operations of OpenAPI documents as
+	// well as their component schemas, e.g. CreatePetRequest and CreatePet201Response.
+	OpenAPIBodies bool
+	// SkipMetaSchema generates types from JSON schemas which are not valid against the meta-schema named by their
+	// $schema instead of failing with their *MetaSchemaError.
+	SkipMetaSchema bool
+}
+
+// MessageType is the type the payload of a named message, e.g. of an AsyncAPI document, is decoded into.
+type MessageType struct {
+	// Name of the message, e.g. "userSignedUp".
+	Name string
+	// Type of the payload.
+	Type *TypeInfo
+	// Package the message is registered in, nil for the default package.
+	Package *PackageMapping
+	// Schema of the payload.
+	Schema *Schema
+}
+
 // Generator will produce structs from the JSON schema.
 type Generator struct {
 	schemas  []*Schema
 	resolver *RefResolver
+	options  GeneratorOptions
 	Structs  map[string]*Struct
 	Aliases  map[string]*Field
+	// Messages are the types of the payloads of named messages, keyed like the structs
+	Messages map[string]*MessageType
 	// cache for reference types; k=url v=type
 	refs        map[string]string
 	anonCount   int
 	structCache map[string][]*Struct
+	// schemas bound to existing go types through the type map
+	mappedTypes map[*Schema]string
+	// documents which could not be loaded for a reference; k=uri v=error
+	loadErrors map[string]error
+	// set once the documents referenced by the inputs are loaded
+	loaded bool
+	// references being resolved, to detect references which refer back to themselves
+	resolving []*Schema
 }
 
 // New creates an instance of a generator which will produce structs.
 func New(schemas ...*Schema) *Generator {
+	return NewWithOptions(GeneratorOptions{}, schemas...)
+}
+
+// NewWithOptions creates an instance of a generator which will produce structs using the given options.
+func NewWithOptions(opts GeneratorOptions, schemas ...*Schema) *Generator {
 	return &Generator{
 		schemas:     schemas,
 		resolver:    NewRefResolver(schemas),
+		options:     opts,
 		Structs:     make(map[string]*Struct),
 		Aliases:     make(map[string]*Field),
+		Messages:    make(map[string]*MessageType),
 		refs:        make(map[string]string),
 		structCache: make(map[string][]*Struct),
+		mappedTypes: make(map[*Schema]string),
+		loadErrors:  make(map[string]error),
 	}
 }
 
 // CreateTypes creates types from the JSON schemas, keyed by the golang name.
 func (g *Generator) CreateTypes() (err error) {
-	if err := g.resolver.Init(); err != nil {
+	if err := g.loadDocuments(); err != nil {
+		return err
+	}
+	if !g.options.SkipMetaSchema {
+		for _, schema := range g.schemas {
+			if schema.MetaSchemaError != nil {
+				return schema.MetaSchemaError
+			}
+		}
+	}
+	if err := g.mapTypes(); err != nil {
 		return err
 	}
 
 	// extract the types
 	for _, schema := range g.schemas {
+		if _, mapped := g.mappedTypes[schema]; mapped {
+			// the whole document is bound to an existing type
+			continue
+		}
+		if schema.OpenAPI != "" || schema.AsyncAPI != "" {
+			// an OpenAPI or AsyncAPI document has no type of its own, only its components, bodies and messages
+			if err := g.processAPIDocument(schema); err != nil {
+				return err
+			}
+			continue
+		}
+		if schema.CRD != "" {
+			// nor has a CustomResourceDefinition, only the versions of its resource
+			for _, cr := range schema.CustomResources {
+				if _, err := g.processNamedType(cr.Name, cr.Schema); err != nil {
+					return err
+				}
+			}
+			continue
+		}
 		name := g.getSchemaName("", schema)
 		rootType, err := g.processSchema(name, schema)
-		rootType.isRootType = true
 		if err != nil {
 			return err
 		}
-		// ugh: if it was anything but a struct the type will not be the name...
-		primType, err := rootType.getPrimitiveTypeName()
-		if err != nil {
+		rootType.isRootType = true
+		if err := g.aliasType(name, rootType, schema); err != nil {
 			return err
 		}
+	}
 
-		if primType != "*"+name {
-			a := NewField(
-				name,
-				"",
-				rootType,
-				false,
-				[]string{schema.Description},
-			)
-			g.Aliases[a.Name] = a
-		}
+	if err := g.checkDiscriminators(); err != nil {
+		return err
 	}
 
 	//consolidate structs and types
-	return g.consolidateStructsAndTypes()
+	if err := g.consolidateStructsAndTypes(); err != nil {
+		return err
+	}
+
+	if len(g.options.Packages) > 0 {
+		return g.checkPackages()
+	}
+	return nil
+}
+
+// aliasType declares the type named name for typ, generated from schema, unless typ is the struct of that name.
+func (g *Generator) aliasType(name string, typ *TypeInfo, schema *Schema) error {
+	// ugh: if it was anything but a struct the type will not be the name...
+	primType, err := typ.getPrimitiveTypeName()
+	if err != nil {
+		return err
+	}
+
+	if primType != "*"+name {
+		a := NewField(
+			name,
+			"",
+			typ,
+			false,
+			[]string{schema.Description},
+		)
+		a.Package = g.packageFor(schema)
+		a.Source = sourceOf(schema)
+		a.Schema = schema
+		g.Aliases[qualifiedKey(a.Package, a.Name)] = a
+	}
+	return nil
 }
 
 func (g *Generator) consolidateStructsAndTypes() error {
@@ -138,8 +250,8 @@ func (g *Generator) addStruct(item *Struct) error {
 	for _, key := range g.getStructKeys() {
 		strct := g.Structs[key]
 
-		//Only alias and merge root types
-		if !strct.TypeInfo.isRootType || !item.TypeInfo.isRootType {
+		//Only alias and merge root types of the same package
+		if !strct.TypeInfo.isRootType || !item.TypeInfo.isRootType || strct.TypeInfo.Package != item.TypeInfo.Package {
 			continue
 		}
 
@@ -153,17 +265,24 @@ func (g *Generator) addStruct(item *Struct) error {
 			//add aliasFor
 			if !strctIsAlias {
 				//only add as alias if this type is not an alias itself
-				g.Aliases[f1FieldName] = NewField(f1FieldName, "", merged.TypeInfo, false, []string{strct.Description})
+				a := NewField(f1FieldName, "", merged.TypeInfo, false, []string{strct.Description})
+				a.Package = merged.TypeInfo.Package
+				a.Source = strct.Source
+				a.Schema = strct.Schema
+				g.Aliases[qualifiedKey(a.Package, f1FieldName)] = a
 				merged.TypeInfo.AddAliasFor(f1FieldName)
 			}
-			g.Aliases[f2FieldName] = NewField(f2FieldName, "", merged.TypeInfo, false, []string{item.Description})
+			a := NewField(f2FieldName, "", merged.TypeInfo, false, []string{item.Description})
+			a.Package = merged.TypeInfo.Package
+			a.Source = item.Source
+			a.Schema = item.Schema
+			g.Aliases[qualifiedKey(a.Package, f2FieldName)] = a
 			merged.TypeInfo.AddAliasFor(f2FieldName)
 
 			merged.TypeInfo.Name = strings.Join(merged.TypeInfo.aliasFor, "_")
 			merged.Description = fmt.Sprintf("\nAliased for: %s", strings.Join(merged.TypeInfo.aliasFor, ", "))
 
-			mergedFieldName := merged.TypeInfo.String()
-			g.Structs[mergedFieldName] = merged
+			g.Structs[qualifiedKey(merged.TypeInfo.Package, merged.TypeInfo.String())] = merged
 			delete(g.Structs, key)
 
 			return nil
@@ -171,7 +290,7 @@ func (g *Generator) addStruct(item *Struct) error {
 	}
 
 	//no aliasing has occured, add struct
-	key := item.TypeInfo.String()
+	key := qualifiedKey(item.TypeInfo.Package, item.TypeInfo.String())
 	if _, ok := g.Structs[key]; ok {
 		return fmt.Errorf("struct with the name '%s' already exists", key)
 	}
@@ -179,16 +298,115 @@ func (g *Generator) addStruct(item *Struct) error {
 	return nil
 }
 
-// process a block of definitions
+// process a block of definitions, or $defs
 func (g *Generator) processDefinitions(schema *Schema) error {
-	for key, subSchema := range schema.Definitions {
-		if _, err := g.processSchema(GetGolangName(key), subSchema); err != nil {
+	for _, definitions := range []map[string]*Schema{schema.Definitions, schema.Defs} {
+		for key, subSchema := range definitions {
+			if _, err := g.processSchema(GetGolangName(key), subSchema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// process the component schemas of an OpenAPI or AsyncAPI document, the payloads of its messages and, if enabled,
+// the bodies of its operations
+func (g *Generator) processAPIDocument(schema *Schema) error {
+	for _, key := range sortedKeys(schema.Components) {
+		// components which are not objects, e.g. strings or arrays, are declared as named types too
+		if _, err := g.processNamedType(key, schema.Components[key]); err != nil {
 			return err
 		}
 	}
+	for _, m := range schema.Messages {
+		if err := g.processMessage(m); err != nil {
+			return err
+		}
+	}
+	if !g.options.OpenAPIBodies {
+		return nil
+	}
+	for _, body := range schema.Bodies {
+		if body.Schema.Reference != "" {
+			// the body is a component, whose type is used as is
+			continue
+		}
+		if _, err := g.processNamedType(body.Name, body.Schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// process the payload of a message, registering its type under the name of the message
+func (g *Generator) processMessage(m Message) error {
+	var typ *TypeInfo
+	var err error
+	if m.Schema.Reference != "" {
+		// the payload is a component, whose type is used as is
+		typ, err = g.processReference(m.Schema)
+	} else {
+		typ, err = g.processNamedType(m.TypeName, m.Schema)
+	}
+	if err != nil {
+		return err
+	}
+	pkg := g.packageFor(m.Schema)
+	key := qualifiedKey(pkg, m.Name)
+	if other, ok := g.Messages[key]; ok {
+		return fmt.Errorf("the message name \"%s\" at \"%s\" is already used at \"%s\"", m.Name, schemaLocation(m.Schema), schemaLocation(other.Schema))
+	}
+	g.Messages[key] = &MessageType{Name: m.Name, Type: typ, Package: pkg, Schema: m.Schema}
 	return nil
 }
 
+// process a schema which is given a type named name, unless it has a title or x-go-name
+func (g *Generator) processNamedType(name string, schema *Schema) (*TypeInfo, error) {
+	name = g.getSchemaName(name, schema)
+	typ, err := g.processSchema(name, schema)
+	if err != nil {
+		return nil, err
+	}
+	return typ, g.aliasType(name, typ, schema)
+}
+
+// checkDiscriminators ensures the references of the discriminator mappings resolve, e.g. that
+// "#/components/schemas/Cat" exists.
+func (g *Generator) checkDiscriminators() error {
+	for _, root := range g.schemas {
+		err := root.Walk(func(_ string, schema *Schema) error {
+			if schema.Discriminator == nil {
+				return nil
+			}
+			values := make([]string, 0, len(schema.Discriminator.Mapping))
+			for value := range schema.Discriminator.Mapping {
+				values = append(values, value)
+			}
+			sort.Strings(values)
+			for _, value := range values {
+				if _, err := g.discriminatorVariant(schema, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discriminatorVariant returns a schema referring to the schema the discriminator of schema maps value onto.
+func (g *Generator) discriminatorVariant(schema *Schema, value string) (*Schema, error) {
+	ref := &Schema{Reference: schema.Discriminator.Mapping[value], Parent: schema}
+	if _, err := g.resolver.GetSchemaByReference(ref); err != nil {
+		return nil, errors.New("discriminator mapping \"" + value + "\" at \"" + schemaLocation(schema) + "\": reference \"" + ref.Reference + "\" not found")
+	}
+	return ref, nil
+}
+
 // process a reference string
 func (g *Generator) processReference(schema *Schema) (*TypeInfo, error) {
 	schemaPath := g.resolver.GetPath(schema)
@@ -197,10 +415,29 @@ func (g *Generator) processReference(schema *Schema) (*TypeInfo, error) {
 	}
 	refSchema, err := g.resolver.GetSchemaByReference(schema)
 	if err != nil {
-		return nil, errors.New("processReference: reference \"" + schema.Reference + "\" not found at \"" + schemaPath + "\"")
+		msg := "processReference: reference \"" + schema.Reference + "\" not found at \"" + schemaPath + "\""
+		if loadErr := g.loadError(schema); loadErr != nil {
+			msg += ": " + loadErr.Error()
+		}
+		return nil, errors.New(msg)
+	}
+	if goType, mapped := g.mappedTypes[refSchema]; mapped {
+		goType, importPath, err := ParseQualifiedType(goType)
+		if err != nil {
+			return nil, err
+		}
+		return NewExternalTypeInfo(goType, importPath), nil
 	}
 	if refSchema.GeneratedType == nil {
 		// reference is not resolved yet. Do that now.
+		for i, s := range g.resolving {
+			if s == refSchema {
+				return nil, g.referenceCycle(append(g.resolving[i:], refSchema))
+			}
+		}
+		g.resolving = append(g.resolving, refSchema)
+		defer func() { g.resolving = g.resolving[:len(g.resolving)-1] }()
+
 		refSchemaName := g.getSchemaName("", refSchema)
 		typ, err := g.processSchema(refSchemaName, refSchema)
 		if err != nil {
@@ -211,15 +448,78 @@ func (g *Generator) processReference(schema *Schema) (*TypeInfo, error) {
 	return refSchema.GeneratedType, nil
 }
 
+// referenceCycle returns the error reporting references which resolve back to the first of them without reaching a
+// type in between, e.g. a definition which is an array of itself.
+func (g *Generator) referenceCycle(chain []*Schema) error {
+	locations := make([]string, len(chain))
+	for i, s := range chain {
+		locations[i] = schemaLocation(s)
+	}
+	return errors.New("processReference: circular reference " + strings.Join(locations, " -> "))
+}
+
+// loadDocuments identifies the input documents and loads the documents they reference, once.
+func (g *Generator) loadDocuments() error {
+	if g.loaded {
+		return nil
+	}
+	if err := g.resolver.Init(); err != nil {
+		return err
+	}
+	if err := g.loadReferences(); err != nil {
+		return err
+	}
+	g.loaded = true
+	return nil
+}
+
+// loadError returns the error which prevented loading the document referenced by schema, if any.
+func (g *Generator) loadError(schema *Schema) error {
+	base, err := url.Parse(schema.GetRoot().ID())
+	if err != nil {
+		return nil
+	}
+	ref, err := url.Parse(schema.Reference)
+	if err != nil {
+		return nil
+	}
+	doc := base.ResolveReference(ref)
+	doc.Fragment = ""
+	return g.loadErrors[doc.String()]
+}
+
 // returns the type refered to by schema after resolving all dependencies
 func (g *Generator) processSchema(schemaName string, schema *Schema) (typ *TypeInfo, err error) {
-	if len(schema.Definitions) > 0 {
+	if len(schema.Definitions) > 0 || len(schema.Defs) > 0 {
 		err = g.processDefinitions(schema)
 		if err != nil {
 			return
 		}
 	}
 
+	// an existing go type is used instead of generating one
+	if schema.GoType != "" {
+		return NewExternalTypeInfo(schema.GoType, schema.GoTypeImport), nil
+	}
+	if goType, mapped := g.mappedTypes[schema]; mapped {
+		goType, importPath, err := ParseQualifiedType(goType)
+		if err != nil {
+			return nil, err
+		}
+		return NewExternalTypeInfo(goType, importPath), nil
+	}
+
+	// a oneOf or anyOf whose discriminator selects the schema, as in OpenAPI, is a union of the mapped schemas
+	if d := schema.Discriminator; d != nil && schema.Mapping == nil && len(d.Mapping) > 0 && len(schema.OneOf)+len(schema.AnyOf) > 0 {
+		variants := make(map[string]*Schema, len(d.Mapping))
+		for value := range d.Mapping {
+			if variants[value], err = g.discriminatorVariant(schema, value); err != nil {
+				return nil, err
+			}
+		}
+		return g.processUnion(schemaName, schema, variants, true)
+	}
+
 	schema.FixMissingTypeValue()
 	// if we have multiple schema types, the golang type will be interface{}
 	typ = NewTypeInfo("interface{}", "interface", false, nil)
@@ -277,7 +577,7 @@ func (g *Generator) processArray(name string, schema *Schema) (typ *TypeInfo, er
 		if err != nil {
 			return nil, err
 		}
-		finalType := NewTypeInfo("", "array", false, subTyp)
+		finalType := NewTypeInfo("", "array", false, nullableElement(schema.Items, subTyp))
 		if err != nil {
 			return nil, err
 		}
@@ -290,7 +590,8 @@ func (g *Generator) processArray(name string, schema *Schema) (typ *TypeInfo, er
 				Contains(schema.Required, name),
 				[]string{schema.Description},
 			)
-			g.Aliases[array.Name] = array
+			array.Package = g.packageFor(schema)
+			g.Aliases[qualifiedKey(array.Package, array.Name)] = array
 		}
 		return finalType, nil
 	}
@@ -302,18 +603,32 @@ func (g *Generator) processArray(name string, schema *Schema) (typ *TypeInfo, er
 // schema: detail incl properties & child objects
 // returns: generated type
 func (g *Generator) processObject(name string, schema *Schema) (typ *TypeInfo, err error) {
+	if schema.Discriminator != nil && schema.Mapping != nil {
+		return g.processUnion(name, schema, schema.Mapping, false)
+	}
 	strct := &Struct{
 		ID:          schema.ID(),
 		TypeInfo:    NewTypeInfo(name, "object", true, nil),
 		Description: schema.Description,
 		Fields:      make(map[string]*Field, len(schema.Properties)),
+		Source:      sourceOf(schema),
+		Schema:      schema,
+	}
+	// fields are keyed by name, so properties becoming the same field would silently replace each other
+	if collisions := fieldNameCollisions(schema); len(collisions) > 0 {
+		return nil, fmt.Errorf("object at \"%s\": %s, set x-go-name to tell them apart", schemaLocation(schema), collisions[0])
 	}
+	strct.TypeInfo.Package = g.packageFor(schema)
 	// cache the object name in case any sub-schemas recursively reference it
 	schema.GeneratedType = strct.TypeInfo
 
-	// regular properties
-	for propKey, prop := range schema.Properties {
+	// regular properties, in schema document order
+	for _, propKey := range schema.OrderedPropertyNames() {
+		prop := schema.Properties[propKey]
 		fieldName := GetGolangName(propKey)
+		if prop.GoName != "" {
+			fieldName = prop.GoName
+		}
 		// calculate sub-schema name here, may not actually be used depending on type of schema!
 		subSchemaName := g.getSchemaName(fieldName, prop)
 		fieldType, err := g.processSchema(subSchemaName, prop)
@@ -327,10 +642,27 @@ func (g *Generator) processObject(name string, schema *Schema) (typ *TypeInfo, e
 			Contains(schema.Required, propKey),
 			[]string{prop.Description},
 		)
+		if err := checkGoTags(prop); err != nil {
+			return nil, err
+		}
+		f.Tags = prop.GoTags
+		f.Schema = prop
+		f.OmitEmpty = prop.GoOmitEmpty
+		f.Pointer = prop.GoPointer
+		f.Embedded = prop.GoEmbed
+		if prop.Nullable && f.Pointer == nil {
+			// null is told apart from the zero value
+			f.Pointer = &prop.Nullable
+		}
+		if f.Embedded || prop.ReadOnly || prop.WriteOnly {
+			// the properties of an embedded type are flattened into this object, so it has no key to require, and
+			// read-only or write-only properties are absent from either requests or responses
+			f.Required = false
+		}
 		if f.Required {
 			strct.GenerateCode = true
 		}
-		strct.Fields[f.Name] = f
+		strct.AddField(f)
 	}
 	// additionalProperties with typed sub-schema
 	if schema.AdditionalProperties != nil && schema.AdditionalProperties.AdditionalPropertiesBool == nil {
@@ -340,13 +672,16 @@ func (g *Generator) processObject(name string, schema *Schema) (typ *TypeInfo, e
 		if err != nil {
 			return nil, err
 		}
+		subTyp = nullableElement(ap, subTyp)
 		mapTyp := NewTypeInfo("string", "map", false, subTyp)
 		// If this object is inline property for another object, and only Contains additional properties, we can
 		// collapse the structure down to a map.
 		//
 		// If this object is a definition and only Contains additional properties, we can't do that or we end up with
 		// no struct
-		isDefinitionObject := strings.HasPrefix(schema.PathElement, "definitions")
+		isDefinitionObject := strings.HasPrefix(schema.PathElement, "definitions") ||
+			strings.HasPrefix(schema.PathElement, "$defs") ||
+			strings.HasPrefix(schema.PathElement, "components/schemas")
 		if len(schema.Properties) == 0 && !isDefinitionObject {
 			// since there are no regular properties, we don't need to emit a struct for this object - return the
 			// additionalProperties map type.
@@ -360,7 +695,7 @@ func (g *Generator) processObject(name string, schema *Schema) (typ *TypeInfo, e
 			false,
 			[]string{},
 		)
-		strct.Fields[f.Name] = f
+		strct.AddField(f)
 		// setting this will cause marshal code to be emitted in Output()
 		strct.GenerateCode = true
 		strct.AdditionalType = subTyp
@@ -377,7 +712,7 @@ func (g *Generator) processObject(name string, schema *Schema) (typ *TypeInfo, e
 				false,
 				[]string{},
 			)
-			strct.Fields[f.Name] = f
+			strct.AddField(f)
 			// setting this will cause marshal code to be emitted in Output()
 			strct.GenerateCode = true
 			strct.AdditionalType = NewTypeInfo("", "interface", false, nil)
@@ -389,12 +724,120 @@ func (g *Generator) processObject(name string, schema *Schema) (typ *TypeInfo, e
 	}
 
 	//store all structs based on unique signature for struct
-	g.structCache[strct.TypeInfo.ShortName()] = append(g.structCache[strct.TypeInfo.ShortName()], strct)
+	cacheKey := qualifiedKey(strct.TypeInfo.Package, strct.TypeInfo.ShortName())
+	g.structCache[cacheKey] = append(g.structCache[cacheKey], strct)
 
 	// objects are always a pointer
 	return strct.TypeInfo, nil
 }
 
+// nameCollision is a property whose field has the name of the field of another property, or a name reserved by the
+// generated code when reserved describes its use.
+type nameCollision struct {
+	key      string
+	other    string
+	reserved string
+	field    string
+}
+
+func (c nameCollision) String() string {
+	if c.reserved != "" {
+		return fmt.Sprintf("the property \"%s\" becomes the field %s, %s", c.key, c.field, c.reserved)
+	}
+	return fmt.Sprintf("the properties \"%s\" and \"%s\" both become the field %s", c.other, c.key, c.field)
+}
+
+// fieldNameCollisions returns the properties of schema, in document order, whose field name is already taken by
+// an earlier property, the field holding additional properties, the generated methods or the fields holding the
+// validation errors of required properties.
+func fieldNameCollisions(schema *Schema) []nameCollision {
+	reserved := map[string]string{}
+	methods := false
+	if ap := schema.AdditionalProperties; ap != nil {
+		methods = true
+		if ap.AdditionalPropertiesBool == nil || *ap.AdditionalPropertiesBool {
+			reserved["AdditionalProperties"] = "which holds the additional properties"
+		}
+	}
+	for _, k := range schema.Required {
+		prop, ok := schema.Properties[k]
+		if !ok || prop.GoEmbed || prop.ReadOnly || prop.WriteOnly {
+			continue
+		}
+		methods = true
+		reserved["_"+k+"_ValidationError"] = fmt.Sprintf("which holds the validation error of the required property \"%s\"", k)
+	}
+	if methods {
+		for _, method := range []string{"MarshalJSON", "UnmarshalJSON", "Validate"} {
+			reserved[method] = "which is the name of a generated method"
+		}
+	}
+
+	var collisions []nameCollision
+	keys := map[string]string{}
+	for _, k := range schema.OrderedPropertyNames() {
+		field := GetGolangName(k)
+		if name := schema.Properties[k].GoName; name != "" {
+			field = name
+		}
+		if use, ok := reserved[field]; ok {
+			collisions = append(collisions, nameCollision{key: k, reserved: use, field: field})
+			continue
+		}
+		if other, ok := keys[field]; ok {
+			collisions = append(collisions, nameCollision{key: k, other: other, field: field})
+			continue
+		}
+		keys[field] = k
+	}
+	return collisions
+}
+
+// name: name of the struct (calculated by caller)
+// schema: a discriminator
+// variants: the schemas of the variants by the value selecting them
+// inline: whether the variants declare the discriminator property themselves
+// returns: generated type, a struct with the tag and a pointer to each variant
+func (g *Generator) processUnion(name string, schema *Schema, variants map[string]*Schema, inline bool) (*TypeInfo, error) {
+	strct := &Struct{
+		ID:           schema.ID(),
+		TypeInfo:     NewTypeInfo(name, "object", true, nil),
+		Description:  schema.Description,
+		Fields:       make(map[string]*Field, len(variants)+1),
+		Source:       sourceOf(schema),
+		Schema:       schema,
+		GenerateCode: true,
+	}
+	strct.TypeInfo.Package = g.packageFor(schema)
+	schema.GeneratedType = strct.TypeInfo
+
+	property := schema.Discriminator.PropertyName
+	tag := NewField(GetGolangName(property), property, NewTypeInfo("string", "string", false, nil), false,
+		[]string{"selects the variant held"})
+	strct.AddField(tag)
+	strct.Union = &Union{Tag: tag, Inline: inline}
+
+	for _, value := range sortedKeys(variants) {
+		variant := variants[value]
+		fieldName := GetGolangName(value)
+		if _, ok := strct.Fields[fieldName]; ok {
+			return nil, fmt.Errorf("the variant \"%s\" of %s clashes with the field %s", value, schemaLocation(schema), fieldName)
+		}
+		typ, err := g.processSchema(g.getSchemaName(name+fieldName, variant), variant)
+		if err != nil {
+			return nil, err
+		}
+		f := NewField(fieldName, "-", typ, false, []string{variant.Description})
+		f.Schema = variant
+		strct.AddField(f)
+		strct.Union.Variants = append(strct.Union.Variants, UnionVariant{Value: value, Field: f})
+	}
+
+	cacheKey := qualifiedKey(strct.TypeInfo.Package, strct.TypeInfo.ShortName())
+	g.structCache[cacheKey] = append(g.structCache[cacheKey], strct)
+	return strct.TypeInfo, nil
+}
+
 func Contains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
@@ -406,6 +849,9 @@ func Contains(s []string, e string) bool {
 
 // return a name for this (sub-)schema.
 func (g *Generator) getSchemaName(keyName string, schema *Schema) string {
+	if schema.GoName != "" {
+		return schema.GoName
+	}
 	if len(schema.Title) > 0 {
 		return GetGolangName(schema.Title)
 	}
@@ -489,12 +935,73 @@ type Struct struct {
 	// Description of the struct
 	Description string
 	Fields      map[string]*Field
+	// FieldOrder lists the keys of Fields in the order the properties appear in the schema.
+	FieldOrder []string
+	// Source is the URI of the schema document the struct was generated from.
+	Source string
+	// Schema the struct was generated from.
+	Schema *Schema
 
 	GenerateCode   bool
 	AdditionalType *TypeInfo
+	// Union is set when the struct holds one of the variants of a discriminator.
+	Union *Union
+}
+
+// Union describes a struct holding one of the variants of a discriminator, which its tag selects.
+type Union struct {
+	// Tag is the field holding the value of the discriminator property.
+	Tag *Field
+	// Variants in the order of their values.
+	Variants []UnionVariant
+	// Inline is set when the variants declare the discriminator property themselves, as those of an OpenAPI
+	// discriminator do, rather than it being removed before they read the object.
+	Inline bool
+}
+
+// UnionVariant is a variant of a union and the value of the tag selecting it.
+type UnionVariant struct {
+	Value string
+	// Field holding the variant, which isn't a property of its own.
+	Field *Field
+}
+
+// AddField adds a field to the struct, recording its position.
+func (s *Struct) AddField(f *Field) {
+	if _, ok := s.Fields[f.Name]; !ok {
+		s.FieldOrder = append(s.FieldOrder, f.Name)
+	}
+	s.Fields[f.Name] = f
+}
+
+// OrderedFieldNames returns the keys of Fields, either alphabetically or in schema order. Fields that are missing
+// from FieldOrder (e.g. structs built in code) follow in alphabetical order.
+func (s *Struct) OrderedFieldNames(alphabetical bool) []string {
+	if alphabetical {
+		return GetOrderedFieldNames(s.Fields)
+	}
+
+	names := make([]string, 0, len(s.Fields))
+	seen := make(map[string]bool, len(s.Fields))
+	for _, k := range s.FieldOrder {
+		if _, ok := s.Fields[k]; ok && !seen[k] {
+			seen[k] = true
+			names = append(names, k)
+		}
+	}
+	for _, k := range GetOrderedFieldNames(s.Fields) {
+		if !seen[k] {
+			names = append(names, k)
+		}
+	}
+	return names
 }
 
 func (s *Struct) unifiedWith(other *Struct) *Struct {
+	if s.Union != nil || other.Union != nil {
+		// the variants are told apart by their values, which the fields don't record
+		return nil
+	}
 	leastFieldsStruct := s
 	mostFieldsStruct := other
 	if len(s.Fields) > len(other.Fields) {
@@ -520,14 +1027,8 @@ func (s *Struct) unifiedWith(other *Struct) *Struct {
 		}
 	}
 
-	fmt.Printf("TypeInfo: %s: Replacing: %s\n", mostFieldsStruct.TypeInfo.Id, leastFieldsStruct.TypeInfo.Id)
 	mostFieldsStruct.TypeInfo.Replaces(leastFieldsStruct.TypeInfo)
 
-	fmt.Printf("TypeInfo: %s: Replaced: %s\n", mostFieldsStruct.TypeInfo.Id, leastFieldsStruct.TypeInfo.Id)
-	for _, f := range mostFieldsStruct.TypeInfo.referencedFields {
-		fmt.Printf("TypeInfo: %s: Replaced: %s: Field Name: %s: Field Id: %s: Field Type: %s\n", mostFieldsStruct.TypeInfo.Id, leastFieldsStruct.TypeInfo.Id, f.Name, f.Id, f.Type.Id)
-	}
-
 	return mostFieldsStruct
 }
 
@@ -544,6 +1045,20 @@ type Field struct {
 	// Required is set to true when the field is required.
 	Required     bool
 	Descriptions []string
+	// Tags overrides the struct tags of the field, keyed by tag name.
+	Tags map[string]string
+	// Schema is the property schema the field was generated from, or the schema of an alias, if any.
+	Schema *Schema
+	// OmitEmpty forces omitempty on or off, otherwise it is applied to fields which are not required.
+	OmitEmpty *bool
+	// Pointer forces the field to be, or not to be, a pointer.
+	Pointer *bool
+	// Embedded embeds the field type in the struct.
+	Embedded bool
+	// Package is the package an alias is generated in, nil for the default package.
+	Package *PackageMapping
+	// Source is the URI of the schema document an alias was generated from.
+	Source string
 }
 
 func NewField(name, jsonName string, info *TypeInfo, required bool, descriptions []string) *Field {
@@ -568,6 +1083,10 @@ type TypeInfo struct {
 	isRootType       bool
 	referencedFields map[string]*Field
 	aliasFor         []string
+	// Import is the import path required by an external type, e.g. "time" for "time.Time".
+	Import string
+	// Package is the package a generated type belongs to, nil for the default package.
+	Package *PackageMapping
 }
 
 func (p *TypeInfo) ShortName() string {
@@ -583,15 +1102,9 @@ func (p *TypeInfo) AddFieldReference(f *Field) {
 	}
 	f.Type = p
 	p.referencedFields[f.Id] = f
-	fmt.Printf("TypeInfo: %s: Added Field: %s\n", p.Id, f.Id)
 }
 func (p *TypeInfo) RemoveFieldReference(f *Field) bool {
 	if field, ok := p.referencedFields[f.Id]; ok {
-		if field == f {
-			fmt.Printf("TypeInfo: %s: Removed Field: %s: Old Type: %s:%s:same object\n", p.Id, f.Id, f.Type.Id, field.Type.Id)
-		} else {
-			fmt.Printf("TypeInfo: %s: Removed Field: %s: Old Type: %s:%s:diff object\n", p.Id, f.Id, f.Type.Id, field.Type.Id)
-		}
 		field.Type = nil
 		delete(p.referencedFields, f.Id)
 		return true
@@ -622,12 +1135,18 @@ func (p *TypeInfo) AddAliasFor(name string) {
 }
 
 func (p *TypeInfo) getPrimitiveTypeName() (name string, err error) {
+	return p.goTypeName(nil)
+}
+
+// goTypeName returns the go type as written in the package pkg. Generated types which belong to another package are
+// qualified with its name. A nil pkg writes all types unqualified.
+func (p *TypeInfo) goTypeName(pkg *packageContext) (name string, err error) {
 	switch p.PrimitiveType {
 	case "array":
 		if p.SubType == nil {
 			return "error_creating_array", errors.New("can't create an array of an empty subtype")
 		}
-		if name, err = p.SubType.getPrimitiveTypeName(); err != nil {
+		if name, err = p.SubType.goTypeName(pkg); err != nil {
 			return "", err
 		} else {
 			return "[]" + name, nil
@@ -644,19 +1163,34 @@ func (p *TypeInfo) getPrimitiveTypeName() (name string, err error) {
 		if p.SubType != nil {
 			return "error_creating_object", errors.New("object cannot contain subtype")
 		}
+		name = p.String()
+		if pkg != nil && p.Package != pkg.mapping {
+			if p.Package == nil {
+				return "error_creating_object", fmt.Errorf("type %s in the default package cannot be used from package %s", name, pkg.mapping.ImportPath)
+			}
+			name = p.Package.PackageName() + "." + name
+		}
 		if p.IsPointer {
-			return "*" + p.String(), nil
+			return "*" + name, nil
 		}
-		return p.String(), nil
+		return name, nil
 	case "string":
 		return "string", nil
+	case "external":
+		return p.Name, nil
 	case "interface":
 		return "interface{}", nil
+	case "pointer":
+		if name, err = p.SubType.goTypeName(pkg); err != nil {
+			return "", err
+		} else {
+			return "*" + name, nil
+		}
 	case "map":
 		if p.Name == "" || p.SubType == nil {
 			return "error_creating_map", fmt.Errorf("map type requires both a name and a subtype: %v", p)
 		}
-		if subName, err := p.SubType.getPrimitiveTypeName(); err != nil {
+		if subName, err := p.SubType.goTypeName(pkg); err != nil {
 			return "", err
 		} else {
 			return fmt.Sprintf("map[%s]%s", p.Name, subName), nil
@@ -667,6 +1201,15 @@ func (p *TypeInfo) getPrimitiveTypeName() (name string, err error) {
 		p.Name, p.SubType)
 }
 
+// nullableElement returns the type of the elements or values of an array or map, which is a pointer to typ when the
+// schema allows null, so null is told apart from the zero value. Objects are pointers already.
+func nullableElement(schema *Schema, typ *TypeInfo) *TypeInfo {
+	if !schema.Nullable || typ.PrimitiveType == "interface" || (typ.PrimitiveType == "object" && typ.IsPointer) {
+		return typ
+	}
+	return NewTypeInfo("", "pointer", false, typ)
+}
+
 func (p *TypeInfo) GetTypeAsString() string {
 	pt, err := p.getPrimitiveTypeName()
 	if err != nil {
@@ -686,3 +1229,10 @@ func NewTypeInfo(name string, primitiveType string, isPointer bool, subType *Typ
 	}
 	return &st
 }
+
+// NewExternalTypeInfo creates type information for an existing go type, e.g. "time.Time" from the "time" package.
+func NewExternalTypeInfo(goType string, importPath string) *TypeInfo {
+	t := NewTypeInfo(goType, "external", false, nil)
+	t.Import = importPath
+	return t
+}
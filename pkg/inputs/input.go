@@ -1,13 +1,18 @@
 package inputs
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"net/url"
 	"os"
 	"path"
+	"regexp"
+	"strings"
 )
 
 // ReadInputFiles from disk and convert to JSON schema.
@@ -29,31 +34,204 @@ func ReadInputFiles(inputFiles []string, schemaKeyRequired bool) ([]*Schema, err
 			Path:   abPath,
 		}
 
-		schemas[i], err = ParseWithSchemaKeyRequired(string(b), &fileURI, schemaKeyRequired)
+		schemas[i], err = ParseSource(file, b, &fileURI, schemaKeyRequired)
 		if err != nil {
-			if jsonError, ok := err.(*json.SyntaxError); ok {
-				line, character, lcErr := LineAndCharacter(b, int(jsonError.Offset))
-				errStr := fmt.Sprintf("cannot parse JSON schema due to a syntax error at %s line %d, character %d: %v\n", file, line, character, jsonError.Error())
-				if lcErr != nil {
-					errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
-				}
-				return nil, errors.New(errStr)
-			}
-			if jsonError, ok := err.(*json.UnmarshalTypeError); ok {
-				line, character, lcErr := LineAndCharacter(b, int(jsonError.Offset))
-				errStr := fmt.Sprintf("the JSON type '%v' cannot be converted into the Go '%v' type on struct '%s', field '%v'. See input file %s line %d, character %d\n", jsonError.Value, jsonError.Type.Name(), jsonError.Struct, jsonError.Field, file, line, character)
-				if lcErr != nil {
-					errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
-				}
-				return nil, errors.New(errStr)
-			}
-			return nil, fmt.Errorf("failed to parse the input JSON schema file %s with error %v", file, err)
+			return nil, err
 		}
 	}
 
 	return schemas, nil
 }
 
+// ReadInputFS reads the schema files at the given paths of fsys, e.g. an embed.FS, and converts them to JSON schema.
+// Each document is identified by a file URI of its path in fsys, e.g. "file:///schemas/order.json" for
+// "schemas/order.json", so relative references between documents resolve within fsys.
+func ReadInputFS(fsys fs.FS, inputFiles []string, schemaKeyRequired bool) ([]*Schema, error) {
+	schemas := make([]*Schema, len(inputFiles))
+	for i, file := range inputFiles {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, errors.New("failed to read the input file with error " + err.Error())
+		}
+
+		schemas[i], err = ParseSource(file, b, FSURI(file), schemaKeyRequired)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return schemas, nil
+}
+
+// FSURI returns the URI identifying the document at name in a fs.FS.
+func FSURI(name string) *url.URL {
+	return &url.URL{Scheme: "file", Path: "/" + path.Clean(name)}
+}
+
+// Source is a schema document held in memory.
+type Source struct {
+	// URI identifies the document, references are resolved relative to it, e.g. "file:///schemas/order.json" or
+	// "https://schemas.example.com/order.json".
+	URI string
+	// Content of the document.
+	Content []byte
+}
+
+// NewSource reads a schema document identified by uri from r.
+func NewSource(uri string, r io.Reader) (Source, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Source{}, fmt.Errorf("failed to read %s with error %v", uri, err)
+	}
+	return Source{URI: uri, Content: b}, nil
+}
+
+// ReadSources converts schema documents held in memory to JSON schema.
+func ReadSources(sources []Source, schemaKeyRequired bool) ([]*Schema, error) {
+	schemas := make([]*Schema, len(sources))
+	for i, source := range sources {
+		uri, err := url.Parse(source.URI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI %s for input schema: %v", source.URI, err)
+		}
+		schemas[i], err = ParseSource(source.URI, source.Content, uri, schemaKeyRequired)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return schemas, nil
+}
+
+// ParseSource parses the schema document b identified by uri, reporting syntax errors with their line and character
+// in the document called name. Documents whose name or URI has a .yaml or .yml extension are parsed as YAML, and
+// OpenAPI and AsyncAPI documents and Kubernetes CustomResourceDefinitions are converted to a schema holding the schemas they
+// define. Documents with a .jtd extension, e.g. "event.jtd.json", are JSON Type Definitions converted to the equivalent
+// schema. JSON schemas are validated against the meta-schema named by their $schema when it is one of drafts 04 to
+// 2020-12. The violations are held in the MetaSchemaError of the schema, or returned when the document can't be parsed.
+func ParseSource(name string, b []byte, uri *url.URL, schemaKeyRequired bool) (*Schema, error) {
+	position := func(offset int) (int, int, error) {
+		return LineAndCharacter(b, offset)
+	}
+	// the position of the value starting at offset, rather than ending before it
+	valuePosition := func(offset int) (int, int, error) {
+		return valueLineAndCharacter(b, offset)
+	}
+	if IsYAML(name) || IsYAML(uri.Path) {
+		doc, err := convertYAML(b)
+		if err != nil {
+			return nil, yamlSyntaxError(name, err)
+		}
+		b, position = doc.json, doc.position
+		valuePosition = func(offset int) (int, int, error) {
+			return doc.position(offset + 1)
+		}
+	}
+
+	var schema *Schema
+	var err error
+	kind := documentFormat(b)
+	if IsJTD(name) || IsJTD(uri.Path) {
+		kind = jtdFormat
+	}
+	switch kind {
+	case jtdFormat:
+		schema, err = parseJTD(b, uri)
+	case openAPIFormat:
+		schema, err = parseOpenAPI(b, uri)
+	case asyncAPIFormat:
+		schema, err = parseAsyncAPI(b, uri)
+	case crdFormat:
+		schema, err = parseCRD(b, uri)
+	default:
+		metaErr := validateMetaSchema(name, b, valuePosition)
+		var violations *MetaSchemaError
+		if metaErr != nil && !errors.As(metaErr, &violations) {
+			return nil, metaErr
+		}
+		schema, err = ParseWithSchemaKeyRequired(string(b), uri, schemaKeyRequired)
+		if violations != nil {
+			if err != nil {
+				// the violations explain why the document can't be parsed better than the unmarshal error
+				return nil, violations
+			}
+			schema.MetaSchemaError = violations
+		}
+	}
+	if err == nil {
+		return schema, nil
+	}
+
+	if jsonError, ok := err.(*json.SyntaxError); ok {
+		line, character, lcErr := position(int(jsonError.Offset))
+		errStr := fmt.Sprintf("cannot parse JSON schema due to a syntax error at %s line %d, character %d: %v\n", name, line, character, jsonError.Error())
+		if lcErr != nil {
+			errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
+		}
+		return nil, errors.New(errStr)
+	}
+	if jsonError, ok := err.(*json.UnmarshalTypeError); ok {
+		line, character, lcErr := position(int(jsonError.Offset))
+		errStr := fmt.Sprintf("the JSON type '%v' cannot be converted into the Go '%v' type on struct '%s', field '%v'. See input file %s line %d, character %d\n", jsonError.Value, jsonError.Type.Name(), jsonError.Struct, jsonError.Field, name, line, character)
+		if lcErr != nil {
+			errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
+		}
+		return nil, errors.New(errStr)
+	}
+	return nil, fmt.Errorf("failed to parse the input JSON schema file %s with error %v", name, err)
+}
+
+// documentKind is the format of an input document.
+type documentKind int
+
+const (
+	jsonSchemaFormat documentKind = iota
+	openAPIFormat
+	asyncAPIFormat
+	crdFormat
+	jtdFormat
+)
+
+// documentFormat returns the format of the JSON document b: an OpenAPI or Swagger document has a top level "openapi"
+// or "swagger" key, an AsyncAPI document an "asyncapi" key, a CustomResourceDefinition is a Kubernetes resource of
+// that kind and anything else is a JSON schema.
+func documentFormat(b []byte) documentKind {
+	var probe struct {
+		OpenAPI    interface{} `json:"openapi"`
+		Swagger    interface{} `json:"swagger"`
+		AsyncAPI   interface{} `json:"asyncapi"`
+		APIVersion interface{} `json:"apiVersion"`
+		Kind       interface{} `json:"kind"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return jsonSchemaFormat
+	}
+	if probe.OpenAPI != nil || probe.Swagger != nil {
+		return openAPIFormat
+	}
+	if probe.AsyncAPI != nil {
+		return asyncAPIFormat
+	}
+	if apiVersion, ok := probe.APIVersion.(string); ok && strings.HasPrefix(apiVersion, "apiextensions.k8s.io/") && probe.Kind == "CustomResourceDefinition" {
+		return crdFormat
+	}
+	return jsonSchemaFormat
+}
+
+// yamlLine matches the line yaml.v3 reports syntax errors at, e.g. "yaml: line 3: did not find expected key".
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlSyntaxError reports an error converting the YAML document called name with its position in the document.
+// The YAML parser only reports the line of syntax errors.
+func yamlSyntaxError(name string, err error) error {
+	if yamlErr, ok := err.(*yamlError); ok {
+		return fmt.Errorf("cannot parse YAML schema at %s line %d, character %d: %s\n", name, yamlErr.line, yamlErr.column, yamlErr.msg)
+	}
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		return fmt.Errorf("cannot parse YAML schema due to a syntax error at %s line %s: %s\n", name, m[1], m[2])
+	}
+	return fmt.Errorf("failed to parse the input YAML schema file %s with error %v", name, err)
+}
+
 func LineAndCharacter(bytes []byte, offset int) (line int, character int, err error) {
 	lf := byte(0x0A)
 
@@ -78,6 +256,15 @@ func LineAndCharacter(bytes []byte, offset int) (line int, character int, err er
 	return 0, 0, fmt.Errorf("couldn't find offset %d in %d bytes", offset, len(bytes))
 }
 
+// valueLineAndCharacter returns the line and character of the value starting at offset.
+func valueLineAndCharacter(b []byte, offset int) (line int, character int, err error) {
+	if offset < 0 || offset >= len(b) {
+		return 0, 0, fmt.Errorf("couldn't find offset %d in %d bytes", offset, len(b))
+	}
+	line = 1 + bytes.Count(b[:offset], []byte{'\n'})
+	return line, offset - bytes.LastIndexByte(b[:offset], '\n'), nil
+}
+
 func abs(name string) (string, error) {
 	if path.IsAbs(name) {
 		return name, nil
@@ -1,9 +1,11 @@
 package inputs
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/url"
+	"sort"
 )
 
 // AdditionalProperties handles additional properties present in the JSON schema.
@@ -32,21 +34,44 @@ type Schema struct {
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.9
 	Definitions map[string]*Schema
 
+	// Defs are the inline re-usable schemas of drafts 2019-09 onwards, which replace Definitions.
+	// https://json-schema.org/draft/2020-12/json-schema-core.html#section-8.2.4
+	Defs map[string]*Schema `json:"-"`
+
 	// Properties, Required and AdditionalProperties describe an object's child instances.
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.5
 	Properties map[string]*Schema
 	Required   []string
 
+	// PropertyOrder lists the keys of Properties in the order they appear in the schema document.
+	PropertyOrder []string `json:"-"`
+
 	// "additionalProperties": {...}
 	AdditionalProperties *AdditionalProperties
 
 	// "additionalProperties": false
 	AdditionalPropertiesBool *bool `json:"-"`
 
+	// PatternProperties, PropertyNames, Dependencies and DependentSchemas are further subschemas applying to an
+	// object's child instances, which the generator only resolves references into. Dependencies holds the schema
+	// dependencies of drafts up to 07, whose property dependencies are not read.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.5
+	PatternProperties map[string]*Schema `json:"-"`
+	PropertyNames     *Schema            `json:"-"`
+	Dependencies      map[string]*Schema `json:"-"`
+	DependentSchemas  map[string]*Schema `json:"-"`
+
 	AnyOf []*Schema
 	AllOf []*Schema
 	OneOf []*Schema
 
+	// Not, If, Then and Else apply subschemas conditionally.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.6
+	Not  *Schema
+	If   *Schema
+	Then *Schema
+	Else *Schema
+
 	// Default can be used to supply a default JSON value associated with a particular schema.
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.10.2
 	Default interface{}
@@ -55,6 +80,28 @@ type Schema struct {
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.10.4
 	Examples []interface{}
 
+	// Enum restricts the value to a fixed set of values.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.1.2
+	Enum []interface{}
+
+	// Minimum, Maximum, ExclusiveMinimum and ExclusiveMaximum restrict numeric values.
+	// ExclusiveMinimum and ExclusiveMaximum are booleans up to draft-04 and numbers from draft-06 onwards.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.2
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum interface{}
+	ExclusiveMaximum interface{}
+
+	// MinLength, MaxLength and Pattern restrict string values.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.3
+	MinLength *int
+	MaxLength *int
+	Pattern   string
+
+	// Format is a semantic validation of string values, e.g. "email" or "date-time".
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.7
+	Format string
+
 	// Reference is a URI reference to a schema.
 	// http://json-schema.org/draft-07/json-schema-core.html#rfc.section.8
 	Reference string `json:"$ref"`
@@ -63,6 +110,91 @@ type Schema struct {
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.4
 	Items *Schema
 
+	// AdditionalItems and Contains are further subschemas applying to an array's items, which the generator only
+	// resolves references into.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.4
+	AdditionalItems *Schema `json:"-"`
+	Contains        *Schema `json:"-"`
+
+	// ReadOnly and WriteOnly mark values which are only sent in responses, or only in requests. Properties marked
+	// with either are never required, since they are absent in the other direction.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.10.3
+	ReadOnly  bool
+	WriteOnly bool
+
+	// Nullable allows null besides the type of the schema, making the generated field, array element or map value a
+	// pointer. It is the OpenAPI 3.0 form of a type array including "null".
+	Nullable bool
+
+	// Discriminator names the property whose value selects the schema of a oneOf or anyOf, as in OpenAPI and
+	// AsyncAPI.
+	Discriminator *Discriminator
+
+	// OpenAPI is the version of the OpenAPI document the schema was converted from, whose schemas are held in
+	// Components and Bodies rather than the schema itself.
+	OpenAPI string `json:"-"`
+
+	// Components are the schemas of an OpenAPI or AsyncAPI document's components/schemas, generated like
+	// definitions.
+	Components map[string]*Schema `json:"-"`
+
+	// Bodies are the schemas of the request and response bodies of an OpenAPI document's operations.
+	Bodies []Body `json:"-"`
+
+	// AsyncAPI is the version of the AsyncAPI document the schema was converted from, whose schemas are held in
+	// Components and Messages rather than the schema itself.
+	AsyncAPI string `json:"-"`
+
+	// Messages are the payload schemas of the messages of an AsyncAPI document.
+	Messages []Message `json:"-"`
+
+	// JTD is set on schemas converted from a JSON Type Definition, whose subschemas are located by its keywords,
+	// e.g. "elements" and "values" rather than "items" and "additionalProperties".
+	JTD bool `json:"-"`
+
+	// Mapping holds the schemas of the variants of a JSON Type Definition discriminator by the value of its
+	// property, which the generated struct holds one of.
+	Mapping map[string]*Schema `json:"-"`
+
+	// CRD is the name of the Kubernetes CustomResourceDefinition the schema was converted from, e.g.
+	// "crontabs.stable.example.com", whose versions are held in CustomResources rather than the schema itself.
+	CRD string `json:"-"`
+
+	// CustomResources are the schemas of the versions of a CustomResourceDefinition.
+	CustomResources []CustomResource `json:"-"`
+
+	// PreserveUnknownFields keeps the properties of an object which aren't specified, IntOrString allows either an
+	// integer or a string and EmbeddedResource marks an object as a Kubernetes resource with its own apiVersion,
+	// kind and metadata.
+	// https://kubernetes.io/docs/tasks/extend-kubernetes/custom-resources/custom-resource-definitions/#specifying-a-structural-schema
+	PreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields"`
+	IntOrString           bool `json:"x-kubernetes-int-or-string"`
+	EmbeddedResource      bool `json:"x-kubernetes-embedded-resource"`
+
+	// GoTags are struct tags set on the generated field, keyed by tag name, e.g. { "validate": "required" }.
+	GoTags map[string]string `json:"x-go-tags"`
+
+	// GoName overrides the name of the generated field or type.
+	GoName string `json:"x-go-name"`
+
+	// GoType uses an existing go type instead of generating one, e.g. "time.Time", with GoTypeImport supplying
+	// its import path, e.g. "time".
+	GoType       string `json:"x-go-type"`
+	GoTypeImport string `json:"x-go-type-import"`
+
+	// GoOmitEmpty forces omitempty on or off for the generated field.
+	GoOmitEmpty *bool `json:"x-go-omitempty"`
+
+	// GoPointer forces the generated field to be, or not to be, a pointer.
+	GoPointer *bool `json:"x-go-pointer"`
+
+	// GoEmbed embeds the type of the generated field in its struct.
+	GoEmbed bool `json:"x-go-embed"`
+
+	// GoEmbedNames are the JSON properties read by the existing go type of an embedded field, e.g. the apiVersion and
+	// kind of the TypeMeta of a Kubernetes object, which are therefore not additional properties.
+	GoEmbedNames []string `json:"-"`
+
 	// NameCount is the number of times the instance name was encountered across the schema.
 	NameCount int `json:"-" `
 
@@ -75,6 +207,13 @@ type Schema struct {
 	// path element - for creating a path by traversing back to the root element
 	PathElement string `json:"-"`
 
+	// SourceURI is the URI the root schema was read from, e.g. its file URI
+	SourceURI string `json:"-"`
+
+	// MetaSchemaError holds the violations of the meta-schema named by the $schema of the document, which the
+	// generator reports unless GeneratorOptions.SkipMetaSchema is set.
+	MetaSchemaError *MetaSchemaError `json:"-"`
+
 	// calculated struct name of this object, cached here
 	GeneratedType *TypeInfo `json:"-"`
 }
@@ -111,6 +250,175 @@ func (ap *AdditionalProperties) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// UnmarshalJSON handles unmarshalling a Schema from JSON, recording the document order of its properties.
+func (schema *Schema) UnmarshalJSON(data []byte) error {
+	type schemaAlias Schema
+	if err := json.Unmarshal(data, (*schemaAlias)(schema)); err != nil {
+		return newOffsetError(err, data)
+	}
+
+	raw := struct {
+		Properties        json.RawMessage
+		Defs              map[string]json.RawMessage `json:"$defs"`
+		PatternProperties map[string]json.RawMessage `json:"patternProperties"`
+		Dependencies      map[string]json.RawMessage `json:"dependencies"`
+		DependentSchemas  map[string]json.RawMessage `json:"dependentSchemas"`
+		PropertyNames     json.RawMessage            `json:"propertyNames"`
+		AdditionalItems   json.RawMessage            `json:"additionalItems"`
+		Contains          json.RawMessage            `json:"contains"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	order, err := objectKeys(raw.Properties)
+	if err != nil {
+		return err
+	}
+	schema.PropertyOrder = order
+
+	// the keywords which may also hold booleans or, for dependencies, arrays of property names only keep their
+	// subschemas
+	for _, keyed := range []struct {
+		raw    map[string]json.RawMessage
+		target *map[string]*Schema
+	}{
+		{raw.Defs, &schema.Defs},
+		{raw.PatternProperties, &schema.PatternProperties},
+		{raw.Dependencies, &schema.Dependencies},
+		{raw.DependentSchemas, &schema.DependentSchemas},
+	} {
+		for k, v := range keyed.raw {
+			sub, err := subschemaObject(v)
+			if err != nil {
+				return err
+			}
+			if sub == nil {
+				continue
+			}
+			if *keyed.target == nil {
+				*keyed.target = map[string]*Schema{}
+			}
+			(*keyed.target)[k] = sub
+		}
+	}
+	for _, single := range []struct {
+		raw    json.RawMessage
+		target **Schema
+	}{
+		{raw.PropertyNames, &schema.PropertyNames},
+		{raw.AdditionalItems, &schema.AdditionalItems},
+		{raw.Contains, &schema.Contains},
+	} {
+		if *single.target, err = subschemaObject(single.raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subschemaObject returns the schema data holds when it is a JSON object, and nil for other values.
+func subschemaObject(data json.RawMessage) (*Schema, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil, nil
+	}
+	sub := &Schema{}
+	if err := json.Unmarshal(data, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// offsetError is a type error while unmarshalling a subschema, whose offset is relative to the JSON of the
+// subschema. absoluteOffset turns it into an offset in the document by finding that JSON in it.
+type offsetError struct {
+	err  *json.UnmarshalTypeError
+	data []byte
+}
+
+func (e *offsetError) Error() string {
+	return e.err.Error()
+}
+
+func newOffsetError(err error, data []byte) error {
+	typeErr, ok := err.(*json.UnmarshalTypeError)
+	if !ok {
+		return err
+	}
+	if typeErr.Struct == "schemaAlias" {
+		typeErr.Struct = "Schema"
+	}
+	return &offsetError{err: typeErr, data: data}
+}
+
+// absoluteOffset returns the type error of an offsetError with its offset in the JSON document data. Should the JSON
+// of the subschema occur several times, the first occurrence is reported.
+func absoluteOffset(err error, data []byte) error {
+	offsetErr, ok := err.(*offsetError)
+	if !ok {
+		return err
+	}
+	typeErr := *offsetErr.err
+	if idx := bytes.Index(data, offsetErr.data); idx >= 0 {
+		typeErr.Offset += int64(idx)
+	}
+	return &typeErr
+}
+
+// objectKeys returns the keys of a JSON object in document order.
+func objectKeys(data json.RawMessage) ([]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// OrderedPropertyNames returns the property keys in schema document order. Properties that were not recorded in
+// PropertyOrder (e.g. schemas built in code) follow in alphabetical order.
+func (schema *Schema) OrderedPropertyNames() []string {
+	names := make([]string, 0, len(schema.Properties))
+	seen := make(map[string]bool, len(schema.Properties))
+	for _, k := range schema.PropertyOrder {
+		if _, ok := schema.Properties[k]; ok && !seen[k] {
+			seen[k] = true
+			names = append(names, k)
+		}
+	}
+
+	var rest []string
+	for k := range schema.Properties {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(names, rest...)
+}
+
 // ID returns the schema URI id.
 func (schema *Schema) ID() string {
 	// prefer "$id" over "id"
@@ -186,12 +494,14 @@ func Parse(schema string, uri *url.URL) (*Schema, error) {
 // ParseWithSchemaKeyRequired parses a JSON schema from a string with a flag to set whether the schema key is required.
 func ParseWithSchemaKeyRequired(schema string, uri *url.URL, schemaKeyRequired bool) (*Schema, error) {
 	s := &Schema{}
-	err := json.Unmarshal([]byte(schema), s)
+	b := []byte(schema)
+	err := json.Unmarshal(b, s)
 
 	if err != nil {
-		return s, err
+		return s, absoluteOffset(err, b)
 	}
 
+	s.SourceURI = uri.String()
 	if s.ID() == "" {
 		s.ID06 = uri.String()
 	}
@@ -209,99 +519,47 @@ func ParseWithSchemaKeyRequired(schema string, uri *url.URL, schemaKeyRequired b
 		return nil, errors.New("$id of document not absolute URI: \"" + uri.String() + "\": \"" + s.ID() + "\"")
 	}
 
-	s.Init()
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
 
 	return s, nil
 }
 
-// Init schema.
-func (schema *Schema) Init() {
+// Init links the subschemas of the document holding schema to their parents, and checks that $schema is only
+// declared at the root of a schema resource.
+func (schema *Schema) Init() error {
 	root := schema.GetRoot()
+	root.PathElement = "#"
 	root.updateParentLinks()
 
-	if err := root.ensureSchemaKeyword(); err != nil {
-		panic(err)
-	}
-
-	root.updatePathElements()
-}
-
-func (schema *Schema) updatePathElements() {
-	if schema.IsRoot() {
-		schema.PathElement = "#"
-	}
-
-	for k, d := range schema.Definitions {
-		d.PathElement = "definitions/" + k
-		d.updatePathElements()
-	}
-
-	for k, p := range schema.Properties {
-		p.PathElement = "properties/" + k
-		p.updatePathElements()
-	}
-
-	if schema.AdditionalProperties != nil {
-		schema.AdditionalProperties.PathElement = "additionalProperties"
-		(*Schema)(schema.AdditionalProperties).updatePathElements()
-	}
-
-	if schema.Items != nil {
-		schema.Items.PathElement = "items"
-		schema.Items.updatePathElements()
-	}
+	return root.ensureSchemaKeyword()
 }
 
+// updateParentLinks links each subschema to the schema holding it, recording its key and path element.
 func (schema *Schema) updateParentLinks() {
-	for k, d := range schema.Definitions {
-		d.JSONKey = k
-		d.Parent = schema
-		d.updateParentLinks()
-	}
-
-	for k, p := range schema.Properties {
-		p.JSONKey = k
-		p.Parent = schema
-		p.updateParentLinks()
-	}
-	if schema.AdditionalProperties != nil {
-		schema.AdditionalProperties.Parent = schema
-		(*Schema)(schema.AdditionalProperties).updateParentLinks()
-	}
-	if schema.Items != nil {
-		schema.Items.Parent = schema
-		schema.Items.updateParentLinks()
-	}
+	schema.Walk(func(_ string, parent *Schema) error {
+		for _, sub := range parent.Subschemas() {
+			sub.Schema.Parent = parent
+			sub.Schema.PathElement = sub.PathElement()
+			if sub.isKeyed() {
+				sub.Schema.JSONKey = sub.Key
+			}
+		}
+		return nil
+	})
 }
 
+// ensureSchemaKeyword checks that the subschemas declaring $schema are the roots of schema resources.
 func (schema *Schema) ensureSchemaKeyword() error {
-	check := func(k string, s *Schema) error {
-		if s.SchemaType != "" {
-			return errors.New("invalid $schema keyword: " + k)
-		}
-		return s.ensureSchemaKeyword()
-	}
-	for k, d := range schema.Definitions {
-		if err := check(k, d); err != nil {
-			return err
-		}
-	}
-	for k, d := range schema.Properties {
-		if err := check(k, d); err != nil {
-			return err
-		}
-	}
-	if schema.AdditionalProperties != nil {
-		if err := check("additionalProperties", (*Schema)(schema.AdditionalProperties)); err != nil {
-			return err
-		}
-	}
-	if schema.Items != nil {
-		if err := check("items", schema.Items); err != nil {
-			return err
+	return schema.Walk(func(pointer string, s *Schema) error {
+		for _, sub := range s.Subschemas() {
+			if sub.Schema.SchemaType != "" && !sub.isResourceRoot() {
+				return errors.New("invalid $schema keyword: " + pointer + "/" + sub.PathElement())
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // FixMissingTypeValue is backwards compatible, guessing the users intention when they didn't specify a type.
@@ -0,0 +1,266 @@
+package inputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// IsJTD reports whether the file or URI path name is a JSON Type Definition (RFC 8927) rather than a JSON schema,
+// i.e. has a .jtd, .jtd.json, .jtd.yaml or .jtd.yml extension.
+func IsJTD(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".jtd", ".jtd.json", ".jtd.yaml", ".jtd.yml"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// jtdTypes maps the types of JSON Type Definition onto a JSON schema type and the go type used for it, if any.
+var jtdTypes = map[string]struct {
+	schemaType string
+	goType     string
+	goImport   string
+}{
+	"boolean":   {schemaType: "boolean"},
+	"string":    {schemaType: "string"},
+	"timestamp": {schemaType: "string", goType: "time.Time", goImport: "time"},
+	"float32":   {schemaType: "number", goType: "float32"},
+	"float64":   {schemaType: "number"},
+	"int8":      {schemaType: "integer", goType: "int8"},
+	"uint8":     {schemaType: "integer", goType: "uint8"},
+	"int16":     {schemaType: "integer", goType: "int16"},
+	"uint16":    {schemaType: "integer", goType: "uint16"},
+	"int32":     {schemaType: "integer", goType: "int32"},
+	"uint32":    {schemaType: "integer", goType: "uint32"},
+}
+
+// jtdSchema is a schema of JSON Type Definition.
+// https://www.rfc-editor.org/rfc/rfc8927#section-2
+type jtdSchema struct {
+	Definitions          map[string]*jtdSchema  `json:"definitions"`
+	Metadata             map[string]interface{} `json:"metadata"`
+	Nullable             bool                   `json:"nullable"`
+	Ref                  *string                `json:"ref"`
+	Type                 string                 `json:"type"`
+	Enum                 []string               `json:"enum"`
+	Elements             *jtdSchema             `json:"elements"`
+	Properties           map[string]*jtdSchema  `json:"properties"`
+	OptionalProperties   map[string]*jtdSchema  `json:"optionalProperties"`
+	AdditionalProperties bool                   `json:"additionalProperties"`
+	Values               *jtdSchema             `json:"values"`
+	Discriminator        *string                `json:"discriminator"`
+	Mapping              map[string]*jtdSchema  `json:"mapping"`
+
+	// keys of properties and optionalProperties in document order
+	propertyOrder []string
+}
+
+// UnmarshalJSON reads a JSON Type Definition, rejecting keywords it doesn't define.
+func (s *jtdSchema) UnmarshalJSON(data []byte) error {
+	keys, err := objectKeys(data)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		switch k {
+		case "definitions", "metadata", "nullable", "ref", "type", "enum", "elements", "properties",
+			"optionalProperties", "additionalProperties", "values", "discriminator", "mapping":
+		default:
+			return fmt.Errorf("unknown JSON Type Definition keyword \"%s\"", k)
+		}
+	}
+
+	type jtdAlias jtdSchema
+	if err := json.Unmarshal(data, (*jtdAlias)(s)); err != nil {
+		return newOffsetError(err, data)
+	}
+
+	raw := struct {
+		Properties         json.RawMessage `json:"properties"`
+		OptionalProperties json.RawMessage `json:"optionalProperties"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, r := range []json.RawMessage{raw.Properties, raw.OptionalProperties} {
+		order, err := objectKeys(r)
+		if err != nil {
+			return err
+		}
+		s.propertyOrder = append(s.propertyOrder, order...)
+	}
+	return nil
+}
+
+// parseJTD converts the JSON Type Definition b, identified by uri, to the JSON schema it is equivalent to. The
+// root type is named after the file, e.g. "Event" for "event.jtd.json", since a JSON Type Definition has no title.
+// Locations in the converted schema use the keywords of JSON Type Definition, e.g. "/elements" rather than
+// "/items".
+func parseJTD(b []byte, uri *url.URL) (*Schema, error) {
+	root := &jtdSchema{}
+	if err := json.Unmarshal(b, root); err != nil {
+		return nil, absoluteOffset(err, b)
+	}
+
+	s, err := root.convert(Pointer{}, root.Definitions)
+	if err != nil {
+		return nil, err
+	}
+	s.ID06 = uri.String()
+	s.SourceURI = uri.String()
+	s.Title = strings.SplitN(path.Base(uri.Path), ".", 2)[0]
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// convert the JSON Type Definition at pointer to the JSON schema it is equivalent to. Refs must name one of the
+// definitions of the root.
+func (s *jtdSchema) convert(pointer Pointer, definitions map[string]*jtdSchema) (*Schema, error) {
+	if s.Definitions != nil && len(pointer) > 0 {
+		return nil, fmt.Errorf("definitions at \"%s\" are only allowed at the root", pointer)
+	}
+	if err := s.checkForm(pointer); err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{JTD: true, Nullable: s.Nullable}
+	if description, ok := s.Metadata["description"].(string); ok {
+		schema.Description = description
+	}
+
+	var err error
+	convert := func(sub *jtdSchema, tokens ...string) *Schema {
+		if err != nil {
+			return nil
+		}
+		var converted *Schema
+		converted, err = sub.convert(pointer.Append(tokens...), definitions)
+		return converted
+	}
+	convertAll := func(m map[string]*jtdSchema, keyword string) map[string]*Schema {
+		converted := make(map[string]*Schema, len(m))
+		for k, sub := range m {
+			converted[k] = convert(sub, keyword, k)
+		}
+		return converted
+	}
+
+	if s.Definitions != nil {
+		schema.Definitions = convertAll(s.Definitions, "definitions")
+	}
+	switch {
+	case s.Ref != nil:
+		if _, ok := definitions[*s.Ref]; !ok {
+			return nil, fmt.Errorf("the ref \"%s\" at \"%s\" is not a definition", *s.Ref, pointer)
+		}
+		schema.Reference = Pointer{"definitions", *s.Ref}.Fragment()
+	case s.Type != "":
+		t, ok := jtdTypes[s.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown type \"%s\" at \"%s\"", s.Type, pointer)
+		}
+		schema.TypeValue = t.schemaType
+		schema.GoType = t.goType
+		schema.GoTypeImport = t.goImport
+		if s.Type == "timestamp" {
+			schema.Format = "date-time"
+		}
+	case s.Enum != nil:
+		schema.TypeValue = "string"
+		for _, value := range s.Enum {
+			schema.Enum = append(schema.Enum, value)
+		}
+	case s.Elements != nil:
+		schema.TypeValue = "array"
+		schema.Items = convert(s.Elements, "elements")
+	case s.Properties != nil || s.OptionalProperties != nil:
+		schema.TypeValue = "object"
+		schema.Properties = convertAll(s.Properties, "properties")
+		for k, sub := range convertAll(s.OptionalProperties, "optionalProperties") {
+			schema.Properties[k] = sub
+		}
+		for k := range s.Properties {
+			schema.Required = append(schema.Required, k)
+		}
+		sort.Strings(schema.Required)
+		schema.PropertyOrder = s.propertyOrder
+		if !s.AdditionalProperties {
+			no := false
+			schema.AdditionalProperties = &AdditionalProperties{AdditionalPropertiesBool: &no}
+		}
+	case s.Values != nil:
+		schema.TypeValue = "object"
+		schema.AdditionalProperties = (*AdditionalProperties)(convert(s.Values, "values"))
+	case s.Discriminator != nil:
+		schema.TypeValue = "object"
+		schema.Discriminator = &Discriminator{PropertyName: *s.Discriminator}
+		schema.Mapping = map[string]*Schema{}
+		for value, variant := range s.Mapping {
+			if (variant.Properties == nil && variant.OptionalProperties == nil) || variant.Nullable {
+				return nil, fmt.Errorf("the mapping \"%s\" at \"%s\" must be a properties form which is not nullable", value, pointer)
+			}
+			if _, ok := variant.Properties[*s.Discriminator]; ok {
+				return nil, fmt.Errorf("the mapping \"%s\" at \"%s\" must not define the discriminator \"%s\"", value, pointer, *s.Discriminator)
+			}
+			if _, ok := variant.OptionalProperties[*s.Discriminator]; ok {
+				return nil, fmt.Errorf("the mapping \"%s\" at \"%s\" must not define the discriminator \"%s\"", value, pointer, *s.Discriminator)
+			}
+			schema.Mapping[value] = convert(variant, "mapping", value)
+		}
+	}
+	return schema, err
+}
+
+// checkForm ensures the schema has one of the forms of JSON Type Definition, i.e. doesn't mix their keywords.
+func (s *jtdSchema) checkForm(pointer Pointer) error {
+	var forms []string
+	if s.Ref != nil {
+		forms = append(forms, "ref")
+	}
+	if s.Type != "" {
+		forms = append(forms, "type")
+	}
+	if s.Enum != nil {
+		forms = append(forms, "enum")
+	}
+	if s.Elements != nil {
+		forms = append(forms, "elements")
+	}
+	if s.Properties != nil || s.OptionalProperties != nil {
+		forms = append(forms, "properties")
+	} else if s.AdditionalProperties {
+		return fmt.Errorf("additionalProperties at \"%s\" requires properties or optionalProperties", pointer)
+	}
+	if s.Values != nil {
+		forms = append(forms, "values")
+	}
+	if s.Discriminator != nil {
+		forms = append(forms, "discriminator")
+	} else if s.Mapping != nil {
+		return fmt.Errorf("mapping at \"%s\" requires a discriminator", pointer)
+	}
+	if len(forms) > 1 {
+		return fmt.Errorf("the schema at \"%s\" mixes the forms %s", pointer, strings.Join(forms, " and "))
+	}
+	if s.Enum != nil {
+		seen := map[string]bool{}
+		for _, value := range s.Enum {
+			if seen[value] {
+				return fmt.Errorf("the enum at \"%s\" repeats \"%s\"", pointer, value)
+			}
+			seen[value] = true
+		}
+		if len(seen) == 0 {
+			return fmt.Errorf("the enum at \"%s\" is empty", pointer)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Layout controls how the generated code of a package is split into files.
+type Layout string
+
+const (
+	// LayoutSingleFile writes all the types of a package into one file.
+	LayoutSingleFile Layout = "single"
+	// LayoutPerSchema writes the types generated from each schema document into their own file.
+	LayoutPerSchema Layout = "schema"
+	// LayoutPerType writes each generated type, along with its methods, into its own file.
+	LayoutPerType Layout = "type"
+)
+
+// HelpersFileName is the file shared helpers, such as ErrFieldRequired, are written to when the code of a package
+// is split into several files.
+const HelpersFileName = "schema_helpers.go"
+
+// MessagesFileName is the file the registry of messages is written to when the code of a package is split into
+// several files.
+const MessagesFileName = "schema_messages.go"
+
+// defaultFileName is the file written by LayoutSingleFile when OutputOptions.FileName is empty.
+const defaultFileName = "models.go"
+
+// ParseLayout parses the name of an output layout, an empty string selects LayoutSingleFile.
+func ParseLayout(s string) (Layout, error) {
+	switch l := Layout(strings.ToLower(strings.TrimSpace(s))); l {
+	case "":
+		return LayoutSingleFile, nil
+	case LayoutSingleFile, LayoutPerSchema, LayoutPerType:
+		return l, nil
+	}
+	return "", fmt.Errorf("unknown output layout '%s', expected one of %s, %s or %s", s, LayoutSingleFile, LayoutPerSchema, LayoutPerType)
+}
+
+// File is a generated go source file.
+type File struct {
+	// Name of the file, e.g. "address.go".
+	Name string
+	// Content of the file.
+	Content []byte
+}
+
+// OutputFiles generates the code of the default package, or of mapping when it is not nil, split into files
+// according to opts.Layout. The files are ordered by name.
+func OutputFiles(g *Generator, mapping *PackageMapping, pkg string, originatingPaths []string, opts OutputOptions) ([]File, error) {
+	pkgName := cleanPackageName(pkg)
+	if mapping != nil {
+		pkgName = mapping.PackageName()
+	}
+	ctx := &packageContext{mapping: mapping}
+
+	layout := opts.Layout
+	if layout == "" {
+		layout = LayoutSingleFile
+	}
+	if layout == LayoutSingleFile {
+		name := opts.FileName
+		if name == "" {
+			name = defaultFileName
+		}
+		buf := new(bytes.Buffer)
+		if err := outputPackage(buf, g, pkgName, ctx, originatingPaths, opts); err != nil {
+			return nil, err
+		}
+		return []File{{Name: name, Content: buf.Bytes()}}, nil
+	}
+
+	structs, aliases := packageTypes(g, ctx)
+	groups, err := groupTypes(layout, structs, aliases)
+	if err != nil {
+		return nil, err
+	}
+	helpers := needsHelpers(structs)
+	if _, ok := groups[HelpersFileName]; helpers && !ok {
+		groups[HelpersFileName] = newFileTypes()
+	}
+	messages := packageMessages(g, ctx)
+	if _, ok := groups[MessagesFileName]; len(messages) > 0 && !ok {
+		groups[MessagesFileName] = newFileTypes()
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]File, 0, len(names))
+	for _, name := range names {
+		group := groups[name]
+		paths := group.sourcePaths()
+		if len(paths) == 0 {
+			paths = originatingPaths
+		}
+		buf := new(bytes.Buffer)
+		var fileMessages []*MessageType
+		if name == MessagesFileName {
+			fileMessages = messages
+		}
+		err := outputTypes(buf, g, pkgName, ctx, paths, group.structs, group.aliases, helpers && name == HelpersFileName, fileMessages, opts)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: name, Content: buf.Bytes()})
+	}
+	return files, nil
+}
+
+// fileTypes are the types written to one file.
+type fileTypes struct {
+	structs map[string]*Struct
+	aliases map[string]*Field
+	sources map[string]bool
+}
+
+func newFileTypes() *fileTypes {
+	return &fileTypes{
+		structs: map[string]*Struct{},
+		aliases: map[string]*Field{},
+		sources: map[string]bool{},
+	}
+}
+
+// sourcePaths returns the schema documents the types were generated from, using paths for local files.
+func (t *fileTypes) sourcePaths() []string {
+	paths := make([]string, 0, len(t.sources))
+	for source := range t.sources {
+		if source == "" {
+			continue
+		}
+		if u, err := url.Parse(source); err == nil && u.Scheme == "file" {
+			source = u.Path
+		}
+		paths = append(paths, source)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// groupTypes assigns each struct and alias to the file it is written to. Types whose file names clash share a file.
+func groupTypes(layout Layout, structs map[string]*Struct, aliases map[string]*Field) (map[string]*fileTypes, error) {
+	var fileName func(typeName, source string) string
+	switch layout {
+	case LayoutPerSchema:
+		fileName = func(_, source string) string { return schemaFileName(source) }
+	case LayoutPerType:
+		fileName = func(typeName, _ string) string { return goFileName(ApplyNamingConvention(typeName, NamingSnake)) }
+	default:
+		return nil, fmt.Errorf("unknown output layout '%s'", layout)
+	}
+
+	groups := map[string]*fileTypes{}
+	group := func(name string) *fileTypes {
+		if _, ok := groups[name]; !ok {
+			groups[name] = newFileTypes()
+		}
+		return groups[name]
+	}
+	for k, s := range structs {
+		t := group(fileName(s.TypeInfo.String(), s.Source))
+		t.structs[k] = s
+		t.sources[s.Source] = true
+	}
+	for k, a := range aliases {
+		t := group(fileName(a.Name, a.Source))
+		t.aliases[k] = a
+		t.sources[a.Source] = true
+	}
+	return groups, nil
+}
+
+// schemaFileName returns the go file name for the schema document at source, e.g. "customer.go" for
+// "file:///schemas/customer.json".
+func schemaFileName(source string) string {
+	p := source
+	if u, err := url.Parse(source); err == nil {
+		p = u.Path
+		if p == "" {
+			p = u.Host
+		}
+	}
+	base := strings.TrimSuffix(path.Base(p), path.Ext(p))
+	return goFileName(ApplyNamingConvention(base, NamingSnake))
+}
+
+// goFileName returns a file name the go tool will build, avoiding names it ignores or treats as tests.
+func goFileName(name string) string {
+	if name == "" || name == "." || name == "/" {
+		name = "schema"
+	}
+	if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+		name = "schema" + name
+	}
+	// a _test suffix, or a GOOS or GOARCH suffix, would exclude the file from normal builds
+	if idx := strings.LastIndex(name, "_"); idx > 0 {
+		if suffix := name[idx+1:]; suffix == "test" || buildConstraintSuffixes[suffix] {
+			name += "_gen"
+		}
+	}
+	return name + ".go"
+}
+
+// buildConstraintSuffixes are the GOOS and GOARCH values the go tool matches against file name suffixes.
+var buildConstraintSuffixes = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true, "openbsd": true,
+	"plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true, "arm64be": true,
+	"loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true,
+	"mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true, "riscv64": true,
+	"s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
+
+// sourceOf returns the URI of the document containing schema.
+func sourceOf(schema *Schema) string {
+	root := schema.GetRoot()
+	if root.SourceURI != "" {
+		return root.SourceURI
+	}
+	return root.ID()
+}
@@ -0,0 +1,477 @@
+package inputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The levels of findings, as in SARIF.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// LintRule is a check made by Lint.
+type LintRule struct {
+	// ID of the rule, e.g. "undefined-required".
+	ID string
+	// Level of the findings of the rule, LevelError or LevelWarning.
+	Level string
+	// Description of what the rule finds.
+	Description string
+}
+
+// LintRules are the rules Lint checks, ordered by ID.
+var LintRules = []LintRule{
+	{ID: "anonymous-type", Level: LevelWarning, Description: "An object without a title is generated as a type named Root or AnonymousN."},
+	{ID: "generator-error", Level: LevelError, Description: "The generator can't create the types of the schemas."},
+	{ID: "ignored-keyword", Level: LevelWarning, Description: "A JSON schema keyword has no effect on the generated code."},
+	{ID: "meta-schema", Level: LevelError, Description: "The schema is not valid against the meta-schema named by its $schema."},
+	{ID: "name-collision", Level: LevelError, Description: "Several properties of an object become the same go field."},
+	{ID: "type-format-mismatch", Level: LevelWarning, Description: "The format of a schema doesn't apply to its type."},
+	{ID: "type-name-collision", Level: LevelWarning, Description: "Several definitions become the same go type, so one of them is renamed."},
+	{ID: "undefined-required", Level: LevelError, Description: "A required property is not defined in properties."},
+	{ID: "unresolved-reference", Level: LevelError, Description: "A $ref doesn't identify a schema."},
+	{ID: "unused-definition", Level: LevelWarning, Description: "A definition is not referenced by any schema."},
+}
+
+// Finding is a problem Lint found in a schema document.
+type Finding struct {
+	// Rule is the ID of the rule which found the problem, e.g. "undefined-required".
+	Rule string
+	// Level of the finding, LevelError or LevelWarning.
+	Level string
+	// File is the name of the document the problem is in, e.g. "schemas/order.json".
+	File string
+	// Pointer to the value at fault, e.g. ["required", "0"].
+	Pointer Pointer
+	// Line and Character of the value in the document, or 0 when it couldn't be found.
+	Line      int
+	Character int
+	// Message describing the problem.
+	Message string
+}
+
+// ignoredKeywords are the keywords of JSON schema, from draft-04 to 2020-12, which the generator doesn't read.
+var ignoredKeywords = []string{
+	"$anchor", "$dynamicAnchor", "$dynamicRef", "$recursiveAnchor", "$recursiveRef", "$vocabulary",
+	"additionalItems", "const", "contains", "contentEncoding", "contentMediaType", "contentSchema", "dependencies",
+	"dependentRequired", "dependentSchemas", "deprecated", "maxContains", "maxItems", "maxProperties", "minContains",
+	"minItems", "minProperties", "multipleOf", "patternProperties", "prefixItems", "propertyNames",
+	"unevaluatedItems", "unevaluatedProperties", "uniqueItems",
+}
+
+// formatTypes are the types of the values the formats of JSON schema and OpenAPI apply to.
+var formatTypes = map[string]string{
+	"date-time": "string", "date": "string", "time": "string", "duration": "string",
+	"email": "string", "idn-email": "string", "hostname": "string", "idn-hostname": "string",
+	"ipv4": "string", "ipv6": "string", "uri": "string", "uri-reference": "string", "iri": "string",
+	"iri-reference": "string", "uri-template": "string", "uuid": "string", "json-pointer": "string",
+	"relative-json-pointer": "string", "regex": "string", "byte": "string", "binary": "string", "password": "string",
+	"int32": "integer", "int64": "integer", "float": "number", "double": "number",
+}
+
+// anonymousName matches the names of types generated for schemas without a name, e.g. "Anonymous3".
+var anonymousName = regexp.MustCompile(`^Anonymous\d+$`)
+
+// LintFiles checks the schema files at the given paths for problems the generator would otherwise paper over. The
+// findings are reported against the paths as given.
+func LintFiles(inputFiles []string, opts GeneratorOptions) ([]Finding, error) {
+	var sources []lintSource
+	for _, file := range inputFiles {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.New("failed to read the input file with error " + err.Error())
+		}
+		abPath, err := abs(file)
+		if err != nil {
+			return nil, errors.New("failed to normalise input path with error " + err.Error())
+		}
+		sources = append(sources, lintSource{name: file, content: b, uri: &url.URL{Scheme: "file", Path: abPath}})
+	}
+	return lint(sources, opts)
+}
+
+// LintFS checks the schema files at the given paths of fsys like LintFiles. Referenced files which are not inputs
+// are loaded from fsys.
+func LintFS(fsys fs.FS, inputFiles []string, opts GeneratorOptions) ([]Finding, error) {
+	if opts.FS == nil {
+		opts.FS = fsys
+	}
+	var sources []lintSource
+	for _, file := range inputFiles {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, errors.New("failed to read the input file with error " + err.Error())
+		}
+		sources = append(sources, lintSource{name: file, content: b, uri: FSURI(file)})
+	}
+	return lint(sources, opts)
+}
+
+// WriteLintText writes the findings one per line, e.g.
+// `order.json:5:9: error: the required property "id" is not defined in properties (#/required/0) [undefined-required]`.
+func WriteLintText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s (%s) [%s]\n", f.File, f.Line, f.Character, f.Level, f.Message, f.Pointer.Fragment(), f.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type lintSource struct {
+	name    string
+	content []byte
+	uri     *url.URL
+}
+
+// lintDocument is an input document being linted.
+type lintDocument struct {
+	name string
+	root *Schema
+	// value is the document decoded as JSON
+	value interface{}
+	// positions are the lines and characters of the values of the document by the string form of their pointer
+	positions map[string][2]int
+}
+
+type linter struct {
+	docs     []*lintDocument
+	findings []Finding
+	// locations of the schemas of the input documents
+	locations map[*Schema]schemaPosition
+}
+
+type schemaPosition struct {
+	doc     *lintDocument
+	pointer string
+}
+
+func lint(sources []lintSource, opts GeneratorOptions) ([]Finding, error) {
+	l := &linter{locations: map[*Schema]schemaPosition{}}
+	var schemas []*Schema
+	for _, source := range sources {
+		schema, err := ParseSource(source.name, source.content, source.uri, false)
+		var metaErr *MetaSchemaError
+		if err == nil && schema.MetaSchemaError != nil && !opts.SkipMetaSchema {
+			metaErr = schema.MetaSchemaError
+		}
+		if metaErr != nil || errors.As(err, &metaErr) {
+			for _, v := range metaErr.Violations {
+				l.findings = append(l.findings, Finding{Rule: "meta-schema", Level: LevelError, File: source.name,
+					Pointer: v.Pointer, Line: v.Line, Character: v.Character, Message: v.Message})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		doc := newLintDocument(source, schema)
+		l.docs = append(l.docs, doc)
+		schemas = append(schemas, schema)
+		doc.root.Walk(func(pointer string, schema *Schema) error {
+			l.locations[schema] = schemaPosition{doc: doc, pointer: pointer}
+			return nil
+		})
+	}
+
+	for _, doc := range l.docs {
+		doc.root.Walk(func(pointer string, schema *Schema) error {
+			l.checkKeywords(doc, pointer, schema)
+			l.checkRequired(doc, pointer, schema)
+			l.checkFormat(doc, pointer, schema)
+			l.checkFieldNames(doc, pointer, schema)
+			l.checkTypeNames(doc, pointer, schema)
+			return nil
+		})
+	}
+
+	// the remaining rules need the types of all the documents, which can't be created when some are missing or have
+	// colliding field names
+	collisions := false
+	for _, f := range l.findings {
+		collisions = collisions || f.Rule == "name-collision"
+	}
+	if len(schemas) == len(sources) && !collisions {
+		g := NewWithOptions(opts, schemas...)
+		if err := g.loadDocuments(); err != nil {
+			l.addGeneratorError(err)
+		} else if l.checkReferences(g) {
+			if err := g.CreateTypes(); err != nil {
+				l.addGeneratorError(err)
+			} else {
+				l.checkDefinitions(g)
+				l.checkAnonymousTypes(g)
+			}
+		}
+	}
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Character != b.Character {
+			return a.Character < b.Character
+		}
+		return a.Rule < b.Rule
+	})
+	return l.findings, nil
+}
+
+// newLintDocument decodes the source of the document parsed as root and finds the positions of its values.
+func newLintDocument(source lintSource, root *Schema) *lintDocument {
+	doc := &lintDocument{name: source.name, root: root, positions: map[string][2]int{}}
+	b := source.content
+	position := func(offset int) (int, int, error) {
+		return valueLineAndCharacter(b, offset)
+	}
+	if IsYAML(source.name) || IsYAML(source.uri.Path) {
+		yamlDoc, err := convertYAML(b)
+		if err != nil {
+			return doc
+		}
+		b = yamlDoc.json
+		position = func(offset int) (int, int, error) {
+			return yamlDoc.position(offset + 1)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	_ = dec.Decode(&doc.value)
+	for pointer, offset := range valueOffsets(b) {
+		if line, character, err := position(offset); err == nil {
+			doc.positions[pointer] = [2]int{line, character}
+		}
+	}
+	return doc
+}
+
+// add reports a finding of rule at pointer in doc.
+func (l *linter) add(rule string, doc *lintDocument, pointer string, format string, args ...interface{}) {
+	level := LevelError
+	for _, r := range LintRules {
+		if r.ID == rule {
+			level = r.Level
+		}
+	}
+	parsed, _ := ParsePointer(pointer)
+	position := doc.positions[pointer]
+	l.findings = append(l.findings, Finding{Rule: rule, Level: level, File: doc.name, Pointer: parsed,
+		Line: position[0], Character: position[1], Message: fmt.Sprintf(format, args...)})
+}
+
+// object returns the JSON object of the schema at pointer in doc, or nil when it isn't in the document as written,
+// e.g. the type meta added to the resources of a CustomResourceDefinition.
+func (doc *lintDocument) object(pointer string) map[string]interface{} {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil
+	}
+	value := doc.value
+	for _, token := range tokens {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			value = container[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(container) {
+				return nil
+			}
+			value = container[i]
+		default:
+			return nil
+		}
+	}
+	object, _ := value.(map[string]interface{})
+	return object
+}
+
+// checkKeywords reports the keywords of schema which the generator ignores.
+func (l *linter) checkKeywords(doc *lintDocument, pointer string, schema *Schema) {
+	if schema.JTD {
+		// JSON Type Definitions have no other keywords
+		return
+	}
+	object := doc.object(pointer)
+	for _, k := range ignoredKeywords {
+		if _, ok := object[k]; ok {
+			l.add("ignored-keyword", doc, pointer+"/"+EscapePointerToken(k), "the keyword \"%s\" has no effect on the generated code", k)
+		}
+	}
+}
+
+// checkRequired reports the required properties of schema which it doesn't define, directly or through allOf.
+func (l *linter) checkRequired(doc *lintDocument, pointer string, schema *Schema) {
+	for i, name := range schema.Required {
+		if _, ok := schema.Properties[name]; ok {
+			continue
+		}
+		defined := false
+		for _, s := range schema.AllOf {
+			if s != nil {
+				_, ok := s.Properties[name]
+				defined = defined || ok
+			}
+		}
+		if !defined {
+			l.add("undefined-required", doc, pointer+"/required/"+strconv.Itoa(i), "the required property \"%s\" is not defined in properties", name)
+		}
+	}
+}
+
+// checkFormat reports a format which applies to values of another type than the schema's.
+func (l *linter) checkFormat(doc *lintDocument, pointer string, schema *Schema) {
+	expected, ok := formatTypes[schema.Format]
+	if !ok {
+		return
+	}
+	types, _ := schema.MultiType()
+	var others []string
+	for _, t := range types {
+		if t == expected || t == "null" || (t == "integer" && expected == "number") {
+			return
+		}
+		others = append(others, t)
+	}
+	if len(others) > 0 {
+		l.add("type-format-mismatch", doc, pointer+"/format", "the format \"%s\" applies to %s values, not %s", schema.Format, expected, joinAlternatives(others))
+	}
+}
+
+// checkFieldNames reports properties of schema which become the same go field.
+func (l *linter) checkFieldNames(doc *lintDocument, pointer string, schema *Schema) {
+	for _, c := range fieldNameCollisions(schema) {
+		l.add("name-collision", doc, pointer+"/properties/"+EscapePointerToken(c.key), "%s", c)
+	}
+}
+
+// checkTypeNames reports definitions of schema which become the same go type, which the generator tells apart by
+// appending a hash to the name of one of them.
+func (l *linter) checkTypeNames(doc *lintDocument, pointer string, schema *Schema) {
+	seen := map[string]string{}
+	for _, sub := range schema.Subschemas() {
+		switch sub.Keyword {
+		case "definitions", "$defs", "components/schemas":
+		default:
+			continue
+		}
+		// references and existing go types declare no type of their own
+		if sub.Schema.Reference != "" || sub.Schema.GoType != "" {
+			continue
+		}
+		name := GetGolangName(sub.Key)
+		if sub.Schema.GoName != "" {
+			name = sub.Schema.GoName
+		} else if sub.Schema.Title != "" {
+			name = GetGolangName(sub.Schema.Title)
+		}
+		if other, ok := seen[name]; ok {
+			l.add("type-name-collision", doc, pointer+"/"+sub.PathElement(), "the definitions \"%s\" and \"%s\" both become the type %s; add a title or x-go-name to tell them apart", other, sub.Key, name)
+			continue
+		}
+		seen[name] = sub.Key
+	}
+}
+
+// checkReferences reports the references of the input documents which don't identify a schema, returning whether
+// they all do.
+func (l *linter) checkReferences(g *Generator) bool {
+	resolved := true
+	for _, doc := range l.docs {
+		doc.root.Walk(func(pointer string, schema *Schema) error {
+			if schema.Reference == "" {
+				return nil
+			}
+			if _, err := g.resolver.GetSchemaByReference(schema); err != nil {
+				resolved = false
+				if loadErr := g.loadError(schema); loadErr != nil {
+					l.add("unresolved-reference", doc, pointer+"/$ref", "the reference \"%s\" can't be resolved: %v", schema.Reference, loadErr)
+				} else {
+					l.add("unresolved-reference", doc, pointer+"/$ref", "the reference \"%s\" doesn't identify a schema", schema.Reference)
+				}
+			}
+			return nil
+		})
+	}
+	return resolved
+}
+
+// addGeneratorError reports the error which stopped the generator against the schema it names, or the root of the
+// first input document when it names none.
+func (l *linter) addGeneratorError(err error) {
+	msg := err.Error()
+	for _, doc := range l.docs {
+		source := sourceOf(doc.root)
+		idx := strings.Index(msg, source)
+		if idx < 0 {
+			continue
+		}
+		pointer := ""
+		if rest := msg[idx+len(source):]; strings.HasPrefix(rest, "#") {
+			pointer = strings.SplitN(rest[1:], "\"", 2)[0]
+			if _, ok := doc.positions[pointer]; !ok {
+				pointer = ""
+			}
+		}
+		l.add("generator-error", doc, pointer, "%s", msg)
+		return
+	}
+	if len(l.docs) > 0 {
+		l.add("generator-error", l.docs[0], "", "%s", msg)
+	}
+}
+
+// checkDefinitions reports the definitions of the input documents which no schema refers to.
+func (l *linter) checkDefinitions(g *Generator) {
+	used := map[*Schema]bool{}
+	for _, root := range g.schemas {
+		root.Walk(func(_ string, schema *Schema) error {
+			if schema.Reference != "" {
+				if target, err := g.resolver.GetSchemaByReference(schema); err == nil {
+					used[target] = true
+				}
+			}
+			return nil
+		})
+	}
+	for _, doc := range l.docs {
+		doc.root.Walk(func(pointer string, schema *Schema) error {
+			for _, sub := range schema.Subschemas() {
+				if (sub.Keyword == "definitions" || sub.Keyword == "$defs") && !used[sub.Schema] {
+					l.add("unused-definition", doc, pointer+"/"+sub.PathElement(), "the definition \"%s\" is not referenced", sub.Key)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// checkAnonymousTypes reports the objects of the input documents generated as types without a name of their own.
+func (l *linter) checkAnonymousTypes(g *Generator) {
+	for _, k := range GetOrderedStructNames(g.Structs) {
+		s := g.Structs[k]
+		name := s.TypeInfo.ShortName()
+		if s.Schema == nil || s.Schema.Title != "" || s.Schema.GoName != "" || (name != "Root" && !anonymousName.MatchString(name)) {
+			continue
+		}
+		if at, ok := l.locations[s.Schema]; ok {
+			l.add("anonymous-type", at.doc, at.pointer, "the object has no title, so its type is named %s; add a title or x-go-name", name)
+		}
+	}
+}
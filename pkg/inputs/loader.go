@@ -0,0 +1,177 @@
+package inputs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ParseSearchPaths parses a comma separated list of directories searched for referenced documents.
+func ParseSearchPaths(s string) []string {
+	var dirs []string
+	for _, dir := range strings.Split(s, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// loadReferences loads the documents referenced by the schemas which were not passed to the generator, e.g.
+// "../common/address.json#/definitions/street", and adds them to the resolver so their types are generated like
+// those of the inputs. The documents loaded are searched for references in turn. Each document is loaded once, so
+// documents referring to each other don't load forever.
+func (g *Generator) loadReferences() error {
+	for i := 0; i < len(g.schemas); i++ {
+		root := g.schemas[i]
+		for _, schema := range references(root) {
+			if err := g.loadReference(root, schema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// references returns the schemas with a $ref in the document of root.
+func references(root *Schema) []*Schema {
+	var refs []*Schema
+	root.Walk(func(_ string, schema *Schema) error {
+		if schema.Reference != "" {
+			refs = append(refs, schema)
+		}
+		return nil
+	})
+	return refs
+}
+
+// loadReference loads the document referenced by schema in the document of root unless it is already known.
+func (g *Generator) loadReference(root, schema *Schema) error {
+	ref, err := url.Parse(schema.Reference)
+	if err != nil {
+		return fmt.Errorf("invalid reference \"%s\" at \"%s\": %v", schema.Reference, schemaLocation(schema), err)
+	}
+	if ref.Scheme == "" && ref.Host == "" && ref.Path == "" {
+		// a fragment of the same document
+		return nil
+	}
+	base, err := url.Parse(root.ID())
+	if err != nil {
+		return err
+	}
+	doc := base.ResolveReference(ref)
+	doc.Fragment = ""
+	if g.resolver.known(doc) {
+		return nil
+	}
+	if _, failed := g.loadErrors[doc.String()]; failed {
+		return nil
+	}
+
+	loaded, tried, err := g.loadDocument(root, ref, doc)
+	if err != nil {
+		return fmt.Errorf("cannot load \"%s\" referenced at \"%s\": %v", doc, schemaLocation(schema), err)
+	}
+	if loaded == nil {
+		// references which can't be resolved are reported when, and if, their types are generated
+		g.loadErrors[doc.String()] = notFound(doc, tried)
+		return nil
+	}
+	g.schemas = append(g.schemas, loaded)
+	return g.resolver.add(loaded, doc)
+}
+
+// loadDocument reads and parses the document referenced by ref, which resolves to doc. It is read from doc, from
+// ref resolved against the file the referring document was read from, from ref below one of the search paths, or
+// by one of the loaders, whichever has it first. No document is returned when none has it, with the places tried.
+func (g *Generator) loadDocument(root *Schema, ref *url.URL, doc *url.URL) (*Schema, []string, error) {
+	var candidates []*url.URL
+	if doc.Scheme == "file" {
+		candidates = append(candidates, doc)
+	}
+	if source, err := url.Parse(root.SourceURI); err == nil && source.Scheme == "file" {
+		u := source.ResolveReference(ref)
+		u.Fragment = ""
+		if u.String() != doc.String() {
+			candidates = append(candidates, u)
+		}
+	}
+	name := ref.Path
+	if ref.IsAbs() || path.IsAbs(name) {
+		// absolute references are looked up by their file name
+		name = path.Base(doc.Path)
+	}
+	for _, dir := range g.options.SearchPaths {
+		u, err := g.searchPathURI(dir, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = append(candidates, u)
+	}
+
+	var tried []string
+	for _, u := range candidates {
+		b, err := g.readFile(u.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			tried = append(tried, u.Path)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s with error %v", u.Path, err)
+		}
+		loaded, err := ParseSource(u.Path, b, u, false)
+		return loaded, nil, err
+	}
+	for _, loader := range g.options.Loaders {
+		b, err := loader.Load(doc)
+		if errors.Is(err, fs.ErrNotExist) {
+			var pathErr *fs.PathError
+			if errors.As(err, &pathErr) {
+				tried = append(tried, pathErr.Path)
+			} else {
+				tried = append(tried, err.Error())
+			}
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		loaded, err := ParseSource(doc.String(), b, doc, false)
+		return loaded, nil, err
+	}
+	return nil, tried, nil
+}
+
+// notFound returns the error reporting that the document doc could not be loaded from any of the paths tried.
+func notFound(doc *url.URL, tried []string) error {
+	if len(tried) == 0 {
+		return fmt.Errorf("%s was not loaded, only files are loaded unless loaders are configured", doc)
+	}
+	return fmt.Errorf("%s was not found, tried %s", doc, strings.Join(tried, ", "))
+}
+
+// searchPathURI returns the file URI of name below the search path dir, which is a path in the generator's file
+// system if it has one, or a directory on disk.
+func (g *Generator) searchPathURI(dir, name string) (*url.URL, error) {
+	if g.options.FS != nil {
+		return FSURI(path.Join(dir, name)), nil
+	}
+	abPath, err := filepath.Abs(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalise search path %s with error %v", dir, err)
+	}
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(abPath)}, nil
+}
+
+// readFile reads the file at the path of a file URI from the generator's file system, or from disk.
+func (g *Generator) readFile(p string) ([]byte, error) {
+	if g.options.FS != nil {
+		return fs.ReadFile(g.options.FS, strings.TrimPrefix(path.Clean(p), "/"))
+	}
+	return os.ReadFile(filepath.FromSlash(p))
+}
@@ -0,0 +1,159 @@
+package inputs
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Loader loads documents referenced by a $ref which are neither inputs nor files found relative to the referring
+// document or on a search path, e.g. "https://schemas.example.com/common/address.json". A loaded document is
+// identified by the URI it was loaded for, so its relative references are loaded through the loaders in turn.
+type Loader interface {
+	// Load returns the content of the document identified by uri, which has no fragment. An error wrapping
+	// fs.ErrNotExist reports that the loader doesn't have the document, so the next loader is tried.
+	Load(uri *url.URL) ([]byte, error)
+}
+
+// LoaderFunc adapts a function to the Loader interface.
+type LoaderFunc func(uri *url.URL) ([]byte, error)
+
+// Load calls f(uri).
+func (f LoaderFunc) Load(uri *url.URL) ([]byte, error) {
+	return f(uri)
+}
+
+// PrefixLoader reads documents whose URI starts with one of its prefixes from the directory the prefix is mapped to,
+// e.g. "https://schemas.example.com/common/address.json" from "vendor-schemas/common/address.json" when
+// "https://schemas.example.com/" is mapped to "vendor-schemas/". The longest matching prefix is used.
+type PrefixLoader struct {
+	// Prefixes maps URI prefixes to directories.
+	Prefixes map[string]string
+	// FS, if set, is the file system the directories are read from instead of the disk.
+	FS fs.FS
+}
+
+// ParseRefMap parses a comma separated list of URI prefix to directory mappings,
+// e.g. "https://schemas.example.com/=./vendor-schemas/".
+func ParseRefMap(s string) (map[string]string, error) {
+	prefixes := map[string]string{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(item, "=")
+		if idx <= 0 || idx == len(item)-1 {
+			return nil, fmt.Errorf("invalid reference mapping '%s', expected <uri prefix>=<directory>", item)
+		}
+		prefix, dir := item[:idx], item[idx+1:]
+		if _, ok := prefixes[prefix]; ok {
+			return nil, fmt.Errorf("duplicate reference mapping for '%s'", prefix)
+		}
+		prefixes[prefix] = dir
+	}
+	return prefixes, nil
+}
+
+// Load reads the document at the path uri is mapped to.
+func (l *PrefixLoader) Load(uri *url.URL) ([]byte, error) {
+	s := uri.String()
+	prefixes := make([]string, 0, len(l.Prefixes))
+	for prefix := range l.Prefixes {
+		if strings.HasPrefix(s, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("no reference mapping for %s: %w", s, fs.ErrNotExist)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	prefix := prefixes[0]
+	rest := path.Clean("/" + strings.TrimPrefix(s, prefix))
+	if l.FS != nil {
+		return fs.ReadFile(l.FS, strings.TrimPrefix(path.Join(l.Prefixes[prefix], rest), "/"))
+	}
+	return os.ReadFile(filepath.Join(l.Prefixes[prefix], filepath.FromSlash(rest)))
+}
+
+// CacheLoader reads documents from a cache directory, in which the document at "https://host/path" is stored as
+// "host/path". Documents which are not cached are loaded by Next, if set, and added to the cache, so a cache
+// populated once lets later builds run without network access.
+type CacheLoader struct {
+	// Dir is the cache directory.
+	Dir string
+	// Next loads the documents which are not cached, e.g. a HTTPLoader. Nothing is added to the cache without it.
+	Next Loader
+}
+
+// Load reads the cached document of uri, loading and caching it with Next when it is missing.
+func (l *CacheLoader) Load(uri *url.URL) ([]byte, error) {
+	if uri.Host == "" {
+		return nil, fmt.Errorf("%s is not cached: %w", uri, fs.ErrNotExist)
+	}
+	// the host names a directory of the cache, so it must not lead out of it
+	if uri.Host == "." || uri.Host == ".." || strings.ContainsAny(uri.Host, `/\`) {
+		return nil, fmt.Errorf("%s can't be cached, its host \"%s\" is not a directory name", uri, uri.Host)
+	}
+	file := filepath.Join(l.Dir, uri.Host, filepath.FromSlash(path.Clean("/"+uri.Path)))
+	b, err := os.ReadFile(file)
+	if err == nil || !os.IsNotExist(err) || l.Next == nil {
+		return b, err
+	}
+
+	b, err = l.Next.Load(uri)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, fmt.Errorf("failed to cache %s with error %v", uri, err)
+	}
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		return nil, fmt.Errorf("failed to cache %s with error %v", uri, err)
+	}
+	return b, nil
+}
+
+// HTTPLoader fetches http and https documents. It makes builds depend on the network, so it is never used unless
+// configured, typically as the Next loader of a CacheLoader.
+type HTTPLoader struct {
+	// Client fetches the documents, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Load fetches the document at uri.
+func (l *HTTPLoader) Load(uri *url.URL) ([]byte, error) {
+	if uri.Scheme != "http" && uri.Scheme != "https" {
+		return nil, fmt.Errorf("%s is not a http URI: %w", uri, fs.ErrNotExist)
+	}
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(uri.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s with error %v", uri, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%s: %s: %w", uri, resp.Status, fs.ErrNotExist)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch %s: %s", uri, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s with error %v", uri, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,554 @@
+package inputs
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metaSchemaFiles are copies of the meta-schemas of drafts 04 to 2020-12 at the path of their URI on json-schema.org,
+// e.g. "metaschemas/draft-07/schema.json" for "http://json-schema.org/draft-07/schema#".
+//
+//go:embed metaschemas
+var metaSchemaFiles embed.FS
+
+// MetaSchemaError reports the violations of the meta-schema named by the $schema of a JSON schema document.
+type MetaSchemaError struct {
+	// Name of the document, e.g. "schemas/order.json".
+	Name string
+	// MetaSchema is the $schema of the document, e.g. "http://json-schema.org/draft-07/schema#".
+	MetaSchema string
+	// Violations in document order.
+	Violations []MetaSchemaViolation
+}
+
+// MetaSchemaViolation is a value of a JSON schema document which its meta-schema doesn't allow.
+type MetaSchemaViolation struct {
+	// Pointer to the value, e.g. ["properties", "id", "required"].
+	Pointer Pointer
+	// Line and Character of the value in the document, or 0 when it couldn't be found.
+	Line      int
+	Character int
+	// Message describing the violation, e.g. "expected array, got boolean".
+	Message string
+}
+
+func (e *MetaSchemaError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "the JSON schema %s is not valid against its meta-schema %s:", e.Name, e.MetaSchema)
+	for _, v := range e.Violations {
+		fmt.Fprintf(&b, "\n  %s line %d, character %d at \"%s\": %s", e.Name, v.Line, v.Character, v.Pointer, v.Message)
+	}
+	return b.String()
+}
+
+// validateMetaSchema validates the JSON schema b against the meta-schema named by its $schema when it is one of the
+// embedded drafts; other meta-schemas aren't validated against. position returns the line and character of the
+// value starting at an offset of b in the document called name. Documents which aren't JSON objects are left to the
+// parser to report.
+func validateMetaSchema(name string, b []byte, position func(offset int) (int, int, error)) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	schemaURI, _ := root["$schema"].(string)
+	uri, err := url.Parse(schemaURI)
+	if err != nil || metaSchemaPath(uri) == "" {
+		return nil
+	}
+
+	meta, err := loadMetaSchema(uri)
+	if err != nil {
+		return err
+	}
+	v := &metaValidator{root: meta}
+	violations := v.validate(meta, doc, Pointer{})
+	if len(violations) == 0 {
+		return nil
+	}
+
+	offsets := valueOffsets(b)
+	result := &MetaSchemaError{Name: name, MetaSchema: schemaURI}
+	seen := map[string]bool{}
+	for _, violation := range violations {
+		key := violation.pointer.String() + "\x00" + violation.message
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mv := MetaSchemaViolation{Pointer: violation.pointer, Message: violation.message}
+		if offset, ok := offsets[violation.pointer.String()]; ok {
+			mv.Line, mv.Character, _ = position(offset)
+		}
+		result.Violations = append(result.Violations, mv)
+	}
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		a, b := result.Violations[i], result.Violations[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+	return result
+}
+
+// metaSchemaPath returns the path of the embedded copy of the meta-schema identified by uri, whatever its scheme and
+// fragment, or "" when there is none.
+func metaSchemaPath(uri *url.URL) string {
+	if uri.Host != "json-schema.org" {
+		return ""
+	}
+	name := path.Join("metaschemas", uri.Path) + ".json"
+	if _, err := fs.Stat(metaSchemaFiles, name); err != nil {
+		return ""
+	}
+	return name
+}
+
+// metaSchemas caches the decoded meta-schemas by their path.
+var metaSchemas = struct {
+	sync.Mutex
+	docs map[string]metaSchema
+}{docs: map[string]metaSchema{}}
+
+// loadMetaSchema returns the embedded meta-schema identified by uri.
+func loadMetaSchema(uri *url.URL) (metaSchema, error) {
+	name := metaSchemaPath(uri)
+	if name == "" {
+		return metaSchema{}, fmt.Errorf("unknown meta-schema %s", uri)
+	}
+
+	metaSchemas.Lock()
+	defer metaSchemas.Unlock()
+	if doc, ok := metaSchemas.docs[name]; ok {
+		return doc, nil
+	}
+	b, err := fs.ReadFile(metaSchemaFiles, name)
+	if err != nil {
+		return metaSchema{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	doc := metaSchema{base: &url.URL{}}
+	*doc.base = *uri
+	doc.base.Fragment = ""
+	if err := dec.Decode(&doc.value); err != nil {
+		return metaSchema{}, fmt.Errorf("failed to read the meta-schema %s: %v", uri, err)
+	}
+	metaSchemas.docs[name] = doc
+	return doc, nil
+}
+
+// metaSchema is a meta-schema, or one of its subschemas, and the URI its references are resolved against.
+type metaSchema struct {
+	value interface{}
+	base  *url.URL
+}
+
+// metaViolation is a value at pointer which a meta-schema doesn't allow. expected holds the types the value should
+// have had when it has the wrong type.
+type metaViolation struct {
+	pointer  Pointer
+	message  string
+	expected []string
+}
+
+// metaValidator validates JSON schema documents against the embedded meta-schemas. Only the keywords the
+// meta-schemas use are implemented.
+type metaValidator struct {
+	// root is the meta-schema validated against, which $recursiveRef and $dynamicRef resolve to: the meta-schemas
+	// only use them to refer to the schema of their dialect.
+	root metaSchema
+}
+
+// validate returns the violations of the schema s by the value at pointer of the validated document.
+func (v *metaValidator) validate(s metaSchema, value interface{}, pointer Pointer) []metaViolation {
+	var violations []metaViolation
+	fail := func(format string, args ...interface{}) {
+		violations = append(violations, metaViolation{pointer: pointer, message: fmt.Sprintf(format, args...)})
+	}
+	sub := func(schema interface{}) metaSchema {
+		return metaSchema{value: schema, base: s.base}
+	}
+
+	schema, ok := s.value.(map[string]interface{})
+	if !ok {
+		if allowed, ok := s.value.(bool); ok && !allowed {
+			fail("no value is allowed")
+		}
+		return violations
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(s.base, ref)
+		if err != nil {
+			fail("%v", err)
+			return violations
+		}
+		violations = append(violations, v.validate(target, value, pointer)...)
+	}
+	for _, k := range []string{"$recursiveRef", "$dynamicRef"} {
+		if _, ok := schema[k]; ok {
+			violations = append(violations, v.validate(v.root, value, pointer)...)
+		}
+	}
+
+	if t, ok := schema["type"]; ok {
+		types := schemaTypes(t)
+		if !hasJSONType(value, types) {
+			return append(violations, metaViolation{
+				pointer:  pointer,
+				message:  fmt.Sprintf("expected %s, got %s", joinAlternatives(types), jsonTypeOf(value)),
+				expected: types,
+			})
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || jsonEqual(value, e)
+		}
+		if !found {
+			var values []string
+			for _, e := range enum {
+				values = append(values, jsonString(e))
+			}
+			fail("%s is not one of %s", jsonString(value), strings.Join(values, ", "))
+		}
+	}
+
+	switch value := value.(type) {
+	case json.Number:
+		n, _ := value.Float64()
+		if minimum, ok := schema["minimum"].(json.Number); ok {
+			m, _ := minimum.Float64()
+			if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && n <= m {
+				fail("%s is not greater than %s", value, minimum)
+			} else if n < m {
+				fail("%s is less than the minimum %s", value, minimum)
+			}
+		}
+		if minimum, ok := schema["exclusiveMinimum"].(json.Number); ok {
+			if m, _ := minimum.Float64(); n <= m {
+				fail("%s is not greater than %s", value, minimum)
+			}
+		}
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+				fail("%s doesn't match the pattern %s", jsonString(value), pattern)
+			}
+		}
+	case []interface{}:
+		if minItems, ok := schema["minItems"].(json.Number); ok {
+			if m, _ := minItems.Int64(); int64(len(value)) < m {
+				fail("has %d items, fewer than the minimum %s", len(value), minItems)
+			}
+		}
+		if unique, _ := schema["uniqueItems"].(bool); unique {
+			for i := range value {
+				for j := i + 1; j < len(value); j++ {
+					if jsonEqual(value[i], value[j]) {
+						fail("the items %d and %d are equal", i, j)
+					}
+				}
+			}
+		}
+		switch items := schema["items"].(type) {
+		case []interface{}:
+			for i, item := range value {
+				if i < len(items) {
+					violations = append(violations, v.validate(sub(items[i]), item, pointer.Append(strconv.Itoa(i)))...)
+				} else if additional, ok := schema["additionalItems"]; ok {
+					violations = append(violations, v.validate(sub(additional), item, pointer.Append(strconv.Itoa(i)))...)
+				}
+			}
+		case nil:
+		default:
+			for i, item := range value {
+				violations = append(violations, v.validate(sub(items), item, pointer.Append(strconv.Itoa(i)))...)
+			}
+		}
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, hasAdditional := schema["additionalProperties"]
+		names, hasNames := schema["propertyNames"]
+		dependencies, _ := schema["dependencies"].(map[string]interface{})
+		for _, k := range sortedJSONKeys(value) {
+			at := pointer.Append(k)
+			if property, ok := properties[k]; ok {
+				violations = append(violations, v.validate(sub(property), value[k], at)...)
+			} else if hasAdditional {
+				if allowed, ok := additional.(bool); ok && !allowed {
+					violations = append(violations, metaViolation{pointer: at, message: fmt.Sprintf("the property %s is not allowed", jsonString(k))})
+				} else {
+					violations = append(violations, v.validate(sub(additional), value[k], at)...)
+				}
+			}
+			if hasNames {
+				for _, violation := range v.validate(sub(names), k, pointer) {
+					violation.message = fmt.Sprintf("the property name %s: %s", jsonString(k), violation.message)
+					violations = append(violations, violation)
+				}
+			}
+			switch dependency := dependencies[k].(type) {
+			case []interface{}:
+				for _, required := range dependency {
+					if name, ok := required.(string); ok {
+						if _, ok := value[name]; !ok {
+							violations = append(violations, metaViolation{pointer: at, message: fmt.Sprintf("%s requires %s", jsonString(k), jsonString(name))})
+						}
+					}
+				}
+			case nil:
+			default:
+				violations = append(violations, v.validate(sub(dependency), value, pointer)...)
+			}
+		}
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, each := range allOf {
+			violations = append(violations, v.validate(sub(each), value, pointer)...)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		violations = append(violations, v.validateAnyOf(s, anyOf, value, pointer)...)
+	}
+	return violations
+}
+
+// validateAnyOf returns no violations when the value matches any of the schemas. Otherwise the violations of the
+// only schema allowing the type of the value are returned, since those are what the author most likely got wrong.
+func (v *metaValidator) validateAnyOf(s metaSchema, anyOf []interface{}, value interface{}, pointer Pointer) []metaViolation {
+	var applicable [][]metaViolation
+	var expected []string
+	for _, each := range anyOf {
+		violations := v.validate(metaSchema{value: each, base: s.base}, value, pointer)
+		if len(violations) == 0 {
+			return nil
+		}
+		if len(violations) == 1 && violations[0].expected != nil && len(violations[0].pointer) == len(pointer) {
+			expected = append(expected, violations[0].expected...)
+			continue
+		}
+		applicable = append(applicable, violations)
+	}
+	switch len(applicable) {
+	case 0:
+		return []metaViolation{{
+			pointer:  pointer,
+			message:  fmt.Sprintf("expected %s, got %s", joinAlternatives(expected), jsonTypeOf(value)),
+			expected: expected,
+		}}
+	case 1:
+		return applicable[0]
+	}
+	return []metaViolation{{pointer: pointer, message: "doesn't match any of the schemas allowed"}}
+}
+
+// resolve returns the subschema of a meta-schema which ref, relative to base, refers to.
+func (v *metaValidator) resolve(base *url.URL, ref string) (metaSchema, error) {
+	uri, err := base.Parse(ref)
+	if err != nil {
+		return metaSchema{}, err
+	}
+	doc, err := loadMetaSchema(uri)
+	if err != nil {
+		return metaSchema{}, err
+	}
+	pointer, err := ParsePointer(uri.Fragment)
+	if err != nil {
+		return metaSchema{}, err
+	}
+	value := doc.value
+	for _, token := range pointer {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			value = container[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(container) {
+				return metaSchema{}, fmt.Errorf("reference %s not found", uri)
+			}
+			value = container[i]
+		default:
+			return metaSchema{}, fmt.Errorf("reference %s not found", uri)
+		}
+	}
+	return metaSchema{value: value, base: doc.base}, nil
+}
+
+// valueOffsets returns the offsets in the JSON document b of its values, by the string form of their pointer.
+func valueOffsets(b []byte) map[string]int {
+	offsets := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	var walk func(pointer Pointer) error
+	walk = func(pointer Pointer) error {
+		// the decoder's offset is at the end of the previous token
+		offset := int(dec.InputOffset())
+		for offset < len(b) && strings.IndexByte(" \t\r\n:,", b[offset]) >= 0 {
+			offset++
+		}
+		offsets[pointer.String()] = offset
+
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := walk(pointer.Append(key.(string))); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(pointer.Append(strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+		_, err = dec.Token()
+		return err
+	}
+	_ = walk(Pointer{})
+	return offsets
+}
+
+// schemaTypes returns the types named by the type keyword t, a type or an array of them.
+func schemaTypes(t interface{}) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, each := range t {
+			if s, ok := each.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// hasJSONType reports whether the decoded JSON value has one of types, integers being numbers too.
+func hasJSONType(value interface{}, types []string) bool {
+	actual := jsonTypeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON schema type of a value decoded with json.Decoder.UseNumber.
+func jsonTypeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if n, err := value.Float64(); err == nil && n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonEqual reports whether the decoded JSON values are equal, numbers being compared by value.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, _ := a.Float64()
+		y, _ := b.Float64()
+		return x == y
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// jsonString returns the JSON encoding of a decoded value, e.g. "\"strng\"".
+func jsonString(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// joinAlternatives joins names as a list of alternatives, e.g. "object, boolean or array".
+func joinAlternatives(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// sortedJSONKeys returns the keys of a decoded JSON object in order.
+func sortedJSONKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,251 @@
+package inputs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Discriminator selects the schema of a oneOf or anyOf by the value of a property, as in OpenAPI.
+// https://spec.openapis.org/oas/v3.1.0#discriminator-object
+type Discriminator struct {
+	// PropertyName is the name of the property holding the value.
+	PropertyName string `json:"propertyName"`
+	// Mapping maps values onto references to the schemas they select, e.g. { "cat": "#/components/schemas/Cat" }.
+	// Values which are not mapped select the schema named by the value.
+	Mapping map[string]string `json:"mapping"`
+}
+
+// UnmarshalJSON reads a discriminator object, or the name of the property as AsyncAPI specifies it.
+func (d *Discriminator) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.PropertyName); err == nil {
+		return nil
+	}
+	type discriminator Discriminator
+	return json.Unmarshal(data, (*discriminator)(d))
+}
+
+// Body is the schema of the request or response body of an OpenAPI operation.
+type Body struct {
+	// Name of the type generated for the body, e.g. "CreatePetRequest" or "CreatePet201Response".
+	Name string
+	// Pointer to the schema in the OpenAPI document, e.g.
+	// ["paths", "/pets", "post", "requestBody", "content", "application/json", "schema"].
+	Pointer Pointer
+	// Schema of the body.
+	Schema *Schema
+}
+
+// openAPIMethods are the operations of a path item, in the order their bodies are generated.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Swagger string `json:"swagger"`
+	Info    struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Components struct {
+		Schemas       map[string]*Schema      `json:"schemas"`
+		RequestBodies map[string]*openAPIBody `json:"requestBodies"`
+		Responses     map[string]*openAPIBody `json:"responses"`
+	} `json:"components"`
+	// Paths hold the path items by path, whose fields are kept raw since only the operations are objects.
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+type openAPIOperation struct {
+	OperationID string                  `json:"operationId"`
+	RequestBody *openAPIBody            `json:"requestBody"`
+	Responses   map[string]*openAPIBody `json:"responses"`
+}
+
+// openAPIBody is a request body or response, or a reference to one in the components of the document.
+type openAPIBody struct {
+	Reference string `json:"$ref"`
+	Content   map[string]struct {
+		Schema *Schema `json:"schema"`
+	} `json:"content"`
+}
+
+// parseOpenAPI converts the OpenAPI 3.0 or 3.1 document b, identified by uri, to a schema holding its component
+// schemas and the schemas of the request and response bodies of its operations. References to them, e.g.
+// "#/components/schemas/Pet", resolve as they do in the document.
+func parseOpenAPI(b []byte, uri *url.URL) (*Schema, error) {
+	doc := openAPIDocument{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, absoluteOffset(err, b)
+	}
+	if doc.Swagger != "" {
+		return nil, fmt.Errorf("swagger %s documents are not supported, only OpenAPI 3.0 and 3.1", doc.Swagger)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.0.") && !strings.HasPrefix(doc.OpenAPI, "3.1.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version \"%s\", only 3.0 and 3.1 are supported", doc.OpenAPI)
+	}
+
+	bodies, err := doc.bodies()
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{
+		ID06:        uri.String(),
+		SourceURI:   uri.String(),
+		Title:       doc.Info.Title,
+		Description: doc.Info.Description,
+		OpenAPI:     doc.OpenAPI,
+		Components:  doc.Components.Schemas,
+		Bodies:      bodies,
+	}
+	if err := s.Init(); err != nil {
+		return nil, err
+	}
+
+	err = s.Walk(func(pointer string, schema *Schema) error {
+		if strings.HasPrefix(doc.OpenAPI, "3.1.") {
+			nullableType(schema)
+		}
+		return normaliseDiscriminator(schema)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// bodies returns the bodies of the operations in the order of their paths and methods, followed by the request
+// bodies and responses of the components, which the operations may refer to.
+func (doc *openAPIDocument) bodies() ([]Body, error) {
+	var bodies []Body
+	add := func(name string, pointer Pointer, body *openAPIBody) {
+		if body == nil || body.Reference != "" {
+			// refers to a body of the components, which is added once under its own name
+			return
+		}
+		if mediaType, ok := jsonMediaType(body.Content); ok {
+			bodies = append(bodies, Body{
+				Name:    name,
+				Pointer: pointer.Append("content", mediaType, "schema"),
+				Schema:  body.Content[mediaType].Schema,
+			})
+		}
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, method := range openAPIMethods {
+			raw, ok := doc.Paths[path][method]
+			if !ok {
+				continue
+			}
+			op := openAPIOperation{}
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("invalid %s operation of the path %s: %v", method, path, err)
+			}
+			name := GetGolangName(op.OperationID)
+			if op.OperationID == "" {
+				name = GetGolangName(method + " " + path)
+			}
+			pointer := Pointer{"paths", path, method}
+			add(name+"Request", pointer.Append("requestBody"), op.RequestBody)
+			for _, code := range sortedBodyKeys(op.Responses) {
+				add(name+GetGolangName(code)+"Response", pointer.Append("responses", code), op.Responses[code])
+			}
+		}
+	}
+
+	for _, k := range sortedBodyKeys(doc.Components.RequestBodies) {
+		add(GetGolangName(k), Pointer{"components", "requestBodies", k}, doc.Components.RequestBodies[k])
+	}
+	for _, k := range sortedBodyKeys(doc.Components.Responses) {
+		add(GetGolangName(k), Pointer{"components", "responses", k}, doc.Components.Responses[k])
+	}
+	return bodies, nil
+}
+
+// jsonMediaType returns the JSON media type of a body: application/json if present, otherwise the first with a
+// +json suffix, e.g. application/problem+json.
+func jsonMediaType(content map[string]struct {
+	Schema *Schema `json:"schema"`
+}) (string, bool) {
+	if c, ok := content["application/json"]; ok && c.Schema != nil {
+		return "application/json", true
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		if strings.HasSuffix(strings.SplitN(mediaType, ";", 2)[0], "+json") && content[mediaType].Schema != nil {
+			return mediaType, true
+		}
+	}
+	return "", false
+}
+
+func sortedBodyKeys(m map[string]*openAPIBody) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nullableType turns a type array of a single type and "null", e.g. ["string", "null"], the OpenAPI 3.1 form of
+// nullable, into that type with Nullable set.
+func nullableType(schema *Schema) {
+	types, ok := schema.TypeValue.([]interface{})
+	if !ok || len(types) != 2 {
+		return
+	}
+	for i, t := range types {
+		if t == "null" {
+			schema.TypeValue = types[1-i]
+			schema.Nullable = true
+			return
+		}
+	}
+}
+
+// normaliseDiscriminator turns the schema names of a discriminator mapping into references, e.g. "Cat" into
+// "#/components/schemas/Cat", and maps the names of the schemas of the oneOf or anyOf which are not mapped
+// explicitly.
+func normaliseDiscriminator(schema *Schema) error {
+	d := schema.Discriminator
+	if d == nil {
+		return nil
+	}
+	if d.PropertyName == "" {
+		return errors.New("discriminator without a propertyName at " + schemaLocation(schema))
+	}
+	if d.Mapping == nil {
+		d.Mapping = map[string]string{}
+	}
+	mapped := map[string]bool{}
+	for value, ref := range d.Mapping {
+		if !strings.ContainsAny(ref, "#/.") {
+			ref = "#/components/schemas/" + ref
+			d.Mapping[value] = ref
+		}
+		mapped[ref] = true
+	}
+	for _, s := range append(append([]*Schema{}, schema.OneOf...), schema.AnyOf...) {
+		if s.Reference == "" || mapped[s.Reference] {
+			continue
+		}
+		value := s.Reference[strings.LastIndex(s.Reference, "/")+1:]
+		if _, ok := d.Mapping[value]; !ok {
+			d.Mapping[value] = s.Reference
+		}
+	}
+	return nil
+}
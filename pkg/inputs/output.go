@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"sort"
 	"strings"
 )
@@ -30,332 +31,301 @@ func GetOrderedStructNames(m map[string]*Struct) []string {
 	return keys
 }
 
+// OutputOptions control how the generated code is written.
+type OutputOptions struct {
+	// Debug annotates each field with the ids of its type and field.
+	Debug bool
+	// AlphabeticalFields orders struct fields by name instead of by their order in the schema.
+	AlphabeticalFields bool
+	// Tags are struct tags emitted in addition to the json tag.
+	Tags []TagOption
+	// ValidateTags emits go-playground/validator tags derived from the schema constraints of each field.
+	ValidateTags bool
+	// Layout controls how the code of a package is split into files, see OutputFiles.
+	Layout Layout
+	// FileName names the file written by LayoutSingleFile, defaulting to "models.go".
+	FileName string
+	// Runtime generates methods calling the schemaruntime package instead of repeating its code in every package.
+	Runtime bool
+	// TemplateDir is a directory of <name>.tmpl files overriding the named templates code is rendered with, e.g.
+	// marshal.tmpl replaces the MarshalJSON method. Other .tmpl files define templates the overrides can use.
+	TemplateDir string
+	// Warnf reports problems which do not stop code generation. Warnings are printed to stderr when it is nil.
+	Warnf func(format string, args ...interface{})
+}
+
+func (opts OutputOptions) warnf(format string, args ...interface{}) {
+	if opts.Warnf != nil {
+		opts.Warnf(format, args...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
+
 // Output generates code and writes to w.
 func Output(w io.Writer, g *Generator, pkg string, originatingPaths []string, debug bool) error {
-	structs := g.Structs
-	aliases := g.Aliases
+	return OutputWithOptions(w, g, pkg, originatingPaths, OutputOptions{Debug: debug})
+}
 
-	fmt.Fprintln(w, "// Code generated by schema-generate. DO NOT EDIT.")
-	fmt.Fprintln(w, "// Source paths: ", strings.Join(originatingPaths, ":"))
-	fmt.Fprintln(w)
-	fmt.Fprintf(w, "package %v\n", cleanPackageName(pkg))
+// OutputWithOptions generates code for the types of the default package using the given options and writes to w.
+func OutputWithOptions(w io.Writer, g *Generator, pkg string, originatingPaths []string, opts OutputOptions) error {
+	return outputPackage(w, g, cleanPackageName(pkg), &packageContext{}, originatingPaths, opts)
+}
 
-	// write all the code into a buffer, compiler functions will return list of imports
-	// write list of imports into main output stream, followed by the code
-	codeBuf := new(bytes.Buffer)
-	imports := make(map[string]bool)
+// OutputPackage generates code for the types placed in a mapped package and writes to w.
+func OutputPackage(w io.Writer, g *Generator, mapping *PackageMapping, originatingPaths []string, opts OutputOptions) error {
+	return outputPackage(w, g, mapping.PackageName(), &packageContext{mapping: mapping}, originatingPaths, opts)
+}
 
-	for _, k := range GetOrderedStructNames(structs) {
-		s := structs[k]
+func outputPackage(w io.Writer, g *Generator, pkgName string, pkg *packageContext, originatingPaths []string, opts OutputOptions) error {
+	structs, aliases := packageTypes(g, pkg)
+	return outputTypes(w, g, pkgName, pkg, originatingPaths, structs, aliases, needsHelpers(structs), packageMessages(g, pkg), opts)
+}
+
+// packageTypes returns the structs and aliases generated in the package pkg.
+func packageTypes(g *Generator, pkg *packageContext) (map[string]*Struct, map[string]*Field) {
+	structs := map[string]*Struct{}
+	for k, s := range g.Structs {
+		if s.TypeInfo.Package == pkg.mapping {
+			structs[k] = s
+		}
+	}
+	aliases := map[string]*Field{}
+	for k, a := range g.Aliases {
+		if a.Package == pkg.mapping {
+			aliases[k] = a
+		}
+	}
+	return structs, aliases
+}
+
+// packageMessages returns the messages registered in the package pkg, ordered by name.
+func packageMessages(g *Generator, pkg *packageContext) []*MessageType {
+	var messages []*MessageType
+	for _, m := range g.Messages {
+		if m.Package == pkg.mapping {
+			messages = append(messages, m)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Name < messages[j].Name })
+	return messages
+}
+
+// needsHelpers reports whether the generated code of structs refers to the shared helpers.
+func needsHelpers(structs map[string]*Struct) bool {
+	for _, s := range structs {
 		if s.GenerateCode {
-			emitMarshalCode(codeBuf, s, imports)
-			emitUnmarshalCode(codeBuf, s, imports)
-			emitValidationCode(codeBuf, s, imports)
+			return true
 		}
 	}
+	return false
+}
+
+// outputTypes writes a file containing the given structs and aliases, along with the shared helpers when helpers is
+// set and the registry of messages when there are any. The code is rendered with the named templates and formatted
+// with go/format before it is written to out.
+func outputTypes(out io.Writer, g *Generator, pkgName string, pkg *packageContext, originatingPaths []string, structs map[string]*Struct, aliases map[string]*Field, helpers bool, messages []*MessageType, opts OutputOptions) error {
+	if err := checkTagOptions(opts); err != nil {
+		return err
+	}
+	// templates register the imports of the code they render, so the declarations are rendered before the
+	// import block is written
+	imports := make(map[string]bool)
+	t, err := loadTemplates(opts, imports)
+	if err != nil {
+		return err
+	}
 
-	if len(imports) > 0 {
-		fmt.Fprintf(w, "\nimport (\n")
-		for k := range imports {
-			fmt.Fprintf(w, "    \"%s\"\n", k)
+	// external types and types of other packages used by fields or aliases
+	for _, s := range structs {
+		for _, f := range s.Fields {
+			addTypeImports(f.Type, pkg, imports)
 		}
-		fmt.Fprintf(w, ")\n")
+	}
+	for _, a := range aliases {
+		addTypeImports(a.Type, pkg, imports)
+	}
+	for _, m := range messages {
+		addTypeImports(m.Type, pkg, imports)
 	}
 
-	//add any additional top level helpers
-	if val := imports["errors"]; val {
-		fmt.Fprintf(w, `
-var ErrFieldRequired = errors.New("field required validation failed")
-`)
+	declBuf := new(bytes.Buffer)
+	var declSpans []sourceSpan
+	if helpers {
+		if err := executeTemplate(declBuf, t, HelpersTemplate, nil); err != nil {
+			return err
+		}
 	}
 
 	for _, k := range GetOrderedFieldNames(aliases) {
 		a := aliases[k]
 
-		pt, err := a.Type.getPrimitiveTypeName()
+		pt, err := a.Type.goTypeName(pkg)
 		if err != nil {
 			return err
 		}
-		fmt.Fprintln(w, "")
-		fmt.Fprintf(w, "// %s\n", a.Name)
-		fmt.Fprintf(w, "type %s %s\n", a.Name, pt)
+		declSpans = append(declSpans, sourceSpan{offset: declBuf.Len(), what: "type " + a.Name})
+		if err := executeTemplate(declBuf, t, AliasTemplate, &AliasData{Alias: a, Name: a.Name, Type: pt}); err != nil {
+			return err
+		}
 	}
 
+	// write code after structs for clarity
+	codeBuf := new(bytes.Buffer)
+	var codeSpans []sourceSpan
 	for _, k := range GetOrderedStructNames(structs) {
 		s := structs[k]
 
-		fmt.Fprintln(w, "")
-		outputNameAndDescriptionComment(s.TypeInfo.String(), s.Description, w)
-		fmt.Fprintf(w, "type %s struct {\n", s.TypeInfo)
-
-		for _, fieldKey := range GetOrderedFieldNames(s.Fields) {
-			f := s.Fields[fieldKey]
+		data, err := newStructData(g, s, pkg, opts)
+		if err != nil {
+			return err
+		}
+		declSpans = append(declSpans, sourceSpan{offset: declBuf.Len(), what: "type " + data.Name})
+		if err := executeTemplate(declBuf, t, StructTemplate, data); err != nil {
+			return err
+		}
 
-			// Only apply omitempty if the field is not required.
-			omitempty := ",omitempty"
-			if f.Required {
-				omitempty = ""
+		if s.GenerateCode {
+			codeSpans = append(codeSpans, sourceSpan{offset: codeBuf.Len(), what: "the methods of " + data.Name})
+			names := []string{MarshalTemplate, UnmarshalTemplate, ValidateTemplate}
+			if s.Union != nil {
+				names = []string{UnionTemplate}
 			}
-
-			if len(f.Descriptions) > 0 {
-				outputFieldDescriptionComment(f.Descriptions, w)
+			for _, name := range names {
+				if err := executeTemplate(codeBuf, t, name, data); err != nil {
+					return err
+				}
 			}
+		}
+	}
 
-			primName, err := f.Type.getPrimitiveTypeName()
+	if len(messages) > 0 {
+		data := &RegistryData{}
+		for _, m := range messages {
+			pt, err := m.Type.goTypeName(pkg)
 			if err != nil {
 				return err
 			}
-
-			if debug {
-				fmt.Fprintf(w, "  %s %s `json:\"%s%s\"` // s:%s, f:%s\n", f.Name, primName, f.JSONName, omitempty, f.Type.Id, f.Id)
-			} else {
-				fmt.Fprintf(w, "  %s %s `json:\"%s%s\"`\n", f.Name, primName, f.JSONName, omitempty)
-			}
-
-			// set marshal required
-			if f.Required {
-				fmt.Fprintf(w, "  _%s_ValidationError error\n\n", f.JSONName)
-			}
+			data.Messages = append(data.Messages, MessageData{Message: m, Name: m.Name, Type: strings.TrimPrefix(pt, "*")})
 		}
+		codeSpans = append(codeSpans, sourceSpan{offset: codeBuf.Len(), what: "the message registry"})
+		if err := executeTemplate(codeBuf, t, RegistryTemplate, data); err != nil {
+			return err
+		}
+	}
 
-		fmt.Fprintln(w, "}")
+	w := new(bytes.Buffer)
+	fmt.Fprintln(w, "// Code generated by schema-generate. DO NOT EDIT.")
+	fmt.Fprintln(w, "// Source paths: ", strings.Join(originatingPaths, ":"))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "package %v\n", pkgName)
+	writeImports(w, imports)
+	fmt.Fprintln(w)
+
+	var spans []sourceSpan
+	for _, span := range declSpans {
+		spans = append(spans, sourceSpan{offset: w.Len() + span.offset, what: span.what})
 	}
+	w.Write(declBuf.Bytes())
+	for _, span := range codeSpans {
+		spans = append(spans, sourceSpan{offset: w.Len() + span.offset, what: span.what})
+	}
+	w.Write(codeBuf.Bytes())
 
-	// write code after structs for clarity
-	_,err := w.Write(codeBuf.Bytes())
+	formatted, err := formatSource(w.Bytes(), spans)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(formatted)
 	return err
 }
 
-func emitMarshalCode(w io.Writer, s *Struct, imports map[string]bool) {
-	imports["bytes"] = true
-	fmt.Fprintf(w,
-		`
-func (strct *%s) MarshalJSON() ([]byte, error) {
-	buf := bytes.NewBuffer(make([]byte, 0))
-	buf.WriteString("{")
-`, s.TypeInfo)
-
-	if len(s.Fields) > 0 {
-		fmt.Fprintf(w, "    comma := false\n")
-		// Marshal all the defined fields
-		for _, fieldKey := range GetOrderedFieldNames(s.Fields) {
-			f := s.Fields[fieldKey]
-			if f.JSONName == "-" {
-				continue
-			}
-			if f.Required {
-				fmt.Fprintf(w, "    // \"%s\" field is required\n", f.Name)
-				// currently only objects are supported
-				if strings.HasPrefix(f.Type.Name, "*") {
-					imports["errors"] = true
-					fmt.Fprintf(w, `    if strct.%s == nil {
-        return nil, errors.New("%s is a required field")
-    }
-`, f.Name, f.JSONName)
-				} else {
-					fmt.Fprintf(w, "    // only required object types supported for marshal checking (for now)\n")
-				}
-			}
-
-			fmt.Fprintf(w,
-				`    // Marshal the "%[1]s" field
-    if comma {
-        buf.WriteString(",")
-    }
-    buf.WriteString("\"%[1]s\": ")
-	if tmp, err := json.Marshal(strct.%[2]s); err != nil {
-		return nil, err
-	} else {
-		buf.Write(tmp)
+// fieldTypeName returns the go type of a field in the package pkg, applying any pointer override.
+func fieldTypeName(f *Field, pkg *packageContext) (string, error) {
+	name, err := f.Type.goTypeName(pkg)
+	if err != nil {
+		return "", err
 	}
-	comma = true
-`, f.JSONName, f.Name)
+	if f.Pointer != nil {
+		if *f.Pointer && !strings.HasPrefix(name, "*") {
+			name = "*" + name
+		} else if !*f.Pointer {
+			name = strings.TrimPrefix(name, "*")
 		}
 	}
-	if s.AdditionalType != nil && s.AdditionalType.PrimitiveType != "boolean" && s.AdditionalType.Name != "false" {
-		imports["fmt"] = true
-
-		if len(s.Fields) == 0 {
-			fmt.Fprintf(w, "    comma := false\n")
-		}
+	return name, nil
+}
 
-		fmt.Fprintf(w, "    // Marshal any additional Properties\n")
-		// Marshal any additional Properties
-		fmt.Fprintf(w, `    for k, v := range strct.AdditionalProperties {
-		if comma {
-			buf.WriteString(",")
-		}
-        buf.WriteString(fmt.Sprintf("\"%%s\":", k))
-		if tmp, err := json.Marshal(v); err != nil {
-			return nil, err
-		} else {
-			buf.Write(tmp)
-		}
-        comma = true
+// embeddedFieldName returns the implicit name of an embedded field, i.e. its type name without pointer or package.
+func embeddedFieldName(typeName string) (string, error) {
+	name := strings.TrimPrefix(typeName, "*")
+	if name == "" || strings.ContainsAny(name, "[]{}* ") {
+		return "", fmt.Errorf("embedded type %s must be a named type or a pointer to a named type", typeName)
 	}
-`)
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
 	}
-
-	fmt.Fprintf(w, `
-	buf.WriteString("}")
-	rv := buf.Bytes()
-	return rv, nil
+	return name, nil
 }
-`)
-}
-
-func emitUnmarshalCode(w io.Writer, s *Struct, imports map[string]bool) {
-	imports["encoding/json"] = true
-	imports["errors"] = true
-	imports["fmt"] = true
 
-	// unmarshal code
-	fmt.Fprintf(w, `
-func (strct *%s) UnmarshalJSON(b []byte) error {
-`, s.TypeInfo)
-	// setup required bools
-	for _, fieldKey := range GetOrderedFieldNames(s.Fields) {
-		f := s.Fields[fieldKey]
-		if f.Required {
-			fmt.Fprintf(w, "    %sReceived := false\n", f.JSONName)
-		}
+// fieldAccessName returns the name used to access a field in generated code.
+func fieldAccessName(f *Field, pkg *packageContext) (string, error) {
+	if !f.Embedded {
+		return f.Name, nil
+	}
+	typeName, err := fieldTypeName(f, pkg)
+	if err != nil {
+		return "", err
 	}
-	// setup initial unmarshal
-	fmt.Fprintf(w, `    var jsonMap map[string]json.RawMessage
-    if err := json.Unmarshal(b, &jsonMap); err != nil {
-        return err
-    }`)
+	return embeddedFieldName(typeName)
+}
 
-	// figure out if we need the "v" output of the range keyword
-	needVal := "_"
-	if len(s.Fields) > 0 || s.AdditionalType != nil {
-		needVal = "v"
+// embeddedJSONNames returns the properties read by the types embedded in s which s doesn't define itself.
+func embeddedJSONNames(g *Generator, s *Struct) []string {
+	own := map[string]bool{}
+	for _, f := range s.Fields {
+		own[f.JSONName] = true
 	}
 
-	// start the loop
-	fmt.Fprintf(w, `
-    // parse all the defined properties
-    for k, %s := range jsonMap {
-        switch k {
-`, needVal)
-	// handle defined properties
-	for _, fieldKey := range GetOrderedFieldNames(s.Fields) {
-		f := s.Fields[fieldKey]
-		if f.JSONName == "-" {
+	var names []string
+	for _, f := range s.Fields {
+		if !f.Embedded {
 			continue
 		}
-		fmt.Fprintf(w, `        case "%s":
-            if err := json.Unmarshal([]byte(v), &strct.%s); err != nil {
-				return err
-            }
-`, f.JSONName, f.Name)
-		if f.Required {
-			fmt.Fprintf(w, "            %sReceived = true\n", f.JSONName)
-		}
-	}
-
-	// handle additional property
-	if s.AdditionalType != nil {
-		if s.AdditionalType.PrimitiveType == "boolean" && s.AdditionalType.Name == "false" {
-			// all unknown properties are not allowed
-			imports["fmt"] = true
-			fmt.Fprintf(w, `        default:
-            return fmt.Errorf("additional property not allowed: \"" + k + "\"")
-`)
-		} else {
-			pt, err := s.AdditionalType.getPrimitiveTypeName()
-			if err != nil {
-				fmt.Printf("error retrieving primitive type for %s (%s): %s\n", s.AdditionalType.Name, s.AdditionalType.Id, err)
+		if f.Schema != nil {
+			for _, name := range f.Schema.GoEmbedNames {
+				if !own[name] {
+					own[name] = true
+					names = append(names, name)
+				}
 			}
-			fmt.Fprintf(w, `        default:
-            // an additional "%s" value
-            var additionalValue %s
-            if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-                return err
-            }
-            if strct.AdditionalProperties == nil {
-                strct.AdditionalProperties = make(map[string]%s, 0)
-            }
-            strct.AdditionalProperties[k]= additionalValue
-`, pt, pt, pt)
 		}
-	}
-	fmt.Fprintf(w, "        }\n") // switch
-	fmt.Fprintf(w, "    }\n")     // for
-
-	// check all Required fields were received
-	for _, fieldKey := range GetOrderedFieldNames(s.Fields) {
-		f := s.Fields[fieldKey]
-		if f.Required {
-			imports["errors"] = true
-			fmt.Fprintf(w, `    // check if %s (a required property) was received
-    if !%sReceived {
-		strct._%s_ValidationError = fmt.Errorf("\"%s\" is required but was not present: %%w", ErrFieldRequired)
-    }
-`, f.JSONName, f.JSONName, f.JSONName, f.Name)
+		for _, other := range g.Structs {
+			if other.TypeInfo != f.Type {
+				continue
+			}
+			for _, ef := range other.Fields {
+				if ef.JSONName != "-" && ef.JSONName != "" && !ef.Embedded && !own[ef.JSONName] {
+					own[ef.JSONName] = true
+					names = append(names, ef.JSONName)
+				}
+			}
 		}
 	}
-
-	fmt.Fprintf(w, "    return nil\n")
-	fmt.Fprintf(w, "}\n") // UnmarshalJSON
+	sort.Strings(names)
+	return names
 }
 
-func emitValidationCode(w io.Writer, s *Struct, imports map[string]bool) {
-	imports["encoding/json"] = true
-	imports["errors"] = true
-	imports["fmt"] = true
-
-	// unmarshal code
-	fmt.Fprintf(w, `
-func (strct *%s) Validate() []error {
-`, s.TypeInfo)
-	fmt.Fprintf(w, "    var allErrors []error\n")
-
-	// setup required bools
-	for _, fieldKey := range GetOrderedFieldNames(s.Fields) {
-		f := s.Fields[fieldKey]
-		if f.Required {
-			fmt.Fprintf(w, `    if strct._%s_ValidationError != nil {
-		allErrors = append(allErrors, strct._%s_ValidationError)
-	}
-`, f.JSONName, f.JSONName)
+// addTypeImports adds the import paths required by a type and its sub types in the package pkg.
+func addTypeImports(t *TypeInfo, pkg *packageContext, imports map[string]bool) {
+	for ; t != nil; t = t.SubType {
+		if t.Import != "" {
+			imports[t.Import] = true
+		}
+		if t.PrimitiveType == "object" && t.Package != nil && t.Package != pkg.mapping {
+			imports[t.Package.ImportPath] = true
 		}
 	}
-
-	fmt.Fprintf(w, `    if len(allErrors) > 0 {
-		return allErrors
-	}
-
-	return nil
-`)
-
-	fmt.Fprintf(w, "}\n") // UnmarshalJSON
-}
-
-func outputNameAndDescriptionComment(name, description string, w io.Writer) {
-	if !strings.Contains(description, "\n") {
-		fmt.Fprintf(w, "// %s %s\n", name, description)
-		return
-	}
-
-	dl := strings.Split(description, "\n")
-	fmt.Fprintf(w, "// %s %s\n", name, strings.Join(dl, "\n// "))
-}
-
-func outputFieldDescriptionComment(descriptions []string, w io.Writer) {
-	description := strings.Join(descriptions, "\n")
-
-	if description == "" {
-		return
-	}
-
-	if !strings.Contains(description, "\n") {
-		fmt.Fprintf(w, "\n  // %s\n", description)
-		return
-	}
-
-	dl := strings.Split(description, "\n")
-	fmt.Fprintf(w, "\n  // %s\n", strings.Join(dl, "\n  // "))
 }
 
 func cleanPackageName(pkg string) string {
@@ -0,0 +1,221 @@
+package inputs
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PackageMapping places the types generated from schemas whose $id or source file URI starts with Prefix into
+// their own go package.
+type PackageMapping struct {
+	// Prefix of the schema $id or source file URI, e.g. "https://schemas.example.com/common/" or
+	// "file:///schemas/common/".
+	Prefix string
+	// ImportPath of the go package, e.g. "github.com/acme/models/common".
+	ImportPath string
+	// Name of the go package. Defaults to the last element of the import path.
+	Name string
+	// Dir is the directory the package is written to.
+	Dir string
+}
+
+// PackageName returns the name of the go package.
+func (m *PackageMapping) PackageName() string {
+	if m.Name != "" {
+		return cleanPackageName(m.Name)
+	}
+	return cleanPackageName(packageName(m.ImportPath))
+}
+
+// ParsePackageMappings parses a comma separated list of package mappings in the form
+// <$id prefix or source directory>=<import path>[:<output directory>], e.g.
+// "https://schemas.example.com/common/=github.com/acme/models/common:./models/common". Source directories are
+// converted to file URI prefixes.
+func ParsePackageMappings(s string) ([]PackageMapping, error) {
+	var mappings []PackageMapping
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(item, "=")
+		if idx <= 0 || idx == len(item)-1 {
+			return nil, fmt.Errorf("invalid package mapping '%s', expected <prefix>=<import path>[:<output directory>]", item)
+		}
+		m := PackageMapping{Prefix: item[:idx], ImportPath: item[idx+1:]}
+		if colon := strings.Index(m.ImportPath, ":"); colon >= 0 {
+			m.Dir = m.ImportPath[colon+1:]
+			m.ImportPath = m.ImportPath[:colon]
+		}
+
+		if u, err := url.Parse(m.Prefix); err != nil || !u.IsAbs() {
+			dir, err := filepath.Abs(m.Prefix)
+			if err != nil {
+				return nil, fmt.Errorf("invalid package mapping prefix '%s': %v", m.Prefix, err)
+			}
+			m.Prefix = (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir) + "/"}).String()
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
+
+// Packages returns the package mappings types are placed into.
+func (g *Generator) Packages() []*PackageMapping {
+	pkgs := make([]*PackageMapping, len(g.options.Packages))
+	for i := range g.options.Packages {
+		pkgs[i] = &g.options.Packages[i]
+	}
+	return pkgs
+}
+
+// packageFor returns the package mapping of the document containing schema, or nil for the default package. The
+// longest matching prefix wins.
+func (g *Generator) packageFor(schema *Schema) *PackageMapping {
+	root := schema.GetRoot()
+	var match *PackageMapping
+	for i := range g.options.Packages {
+		m := &g.options.Packages[i]
+		if !strings.HasPrefix(root.ID(), m.Prefix) && !strings.HasPrefix(root.SourceURI, m.Prefix) {
+			continue
+		}
+		if match == nil || len(m.Prefix) > len(match.Prefix) {
+			match = m
+		}
+	}
+	return match
+}
+
+// packageContext is the package code is generated for.
+type packageContext struct {
+	// mapping of the package, nil for the default package.
+	mapping *PackageMapping
+}
+
+// qualifiedKey returns the key of a type in Structs or Aliases. Types in the default package are keyed by name.
+func qualifiedKey(pkg *PackageMapping, name string) string {
+	if pkg == nil {
+		return name
+	}
+	return pkg.ImportPath + "." + name
+}
+
+// packageDependencies returns, for each package, the packages its types refer to and an example type reference for
+// each dependency, e.g. "Order.Total refers to common.Money".
+func (g *Generator) packageDependencies() map[*PackageMapping]map[*PackageMapping]string {
+	deps := map[*PackageMapping]map[*PackageMapping]string{}
+	add := func(from *PackageMapping, where string, t *TypeInfo) {
+		for ; t != nil; t = t.SubType {
+			if t.PrimitiveType != "object" || t.Package == from {
+				continue
+			}
+			if deps[from] == nil {
+				deps[from] = map[*PackageMapping]string{}
+			}
+			if _, ok := deps[from][t.Package]; !ok {
+				deps[from][t.Package] = fmt.Sprintf("%s refers to %s", where, t.String())
+			}
+		}
+	}
+
+	for _, k := range GetOrderedStructNames(g.Structs) {
+		s := g.Structs[k]
+		for _, fk := range GetOrderedFieldNames(s.Fields) {
+			add(s.TypeInfo.Package, s.TypeInfo.String()+"."+fk, s.Fields[fk].Type)
+		}
+	}
+	for _, k := range GetOrderedFieldNames(g.Aliases) {
+		a := g.Aliases[k]
+		add(a.Package, a.Name, a.Type)
+	}
+	return deps
+}
+
+// checkPackages reports types in mapped packages which refer to the default package, and import cycles between
+// packages.
+func (g *Generator) checkPackages() error {
+	deps := g.packageDependencies()
+
+	var pkgs []*PackageMapping
+	for from, to := range deps {
+		if from != nil {
+			if reason, ok := to[nil]; ok {
+				return fmt.Errorf("package %s cannot import the default package: %s", from.ImportPath, reason)
+			}
+		}
+		pkgs = append(pkgs, from)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return packagePath(pkgs[i]) < packagePath(pkgs[j]) })
+
+	// depth first search for a dependency back onto the current path
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*PackageMapping]int{}
+	var path []*PackageMapping
+	var visit func(p *PackageMapping) error
+	visit = func(p *PackageMapping) error {
+		state[p] = visiting
+		path = append(path, p)
+
+		var next []*PackageMapping
+		for d := range deps[p] {
+			next = append(next, d)
+		}
+		sort.Slice(next, func(i, j int) bool { return packagePath(next[i]) < packagePath(next[j]) })
+
+		for _, d := range next {
+			switch state[d] {
+			case visiting:
+				var cycle, reasons []string
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == d {
+						for _, c := range path[i:] {
+							cycle = append(cycle, packagePath(c))
+						}
+						for j := i; j < len(path); j++ {
+							to := d
+							if j+1 < len(path) {
+								to = path[j+1]
+							}
+							reasons = append(reasons, deps[path[j]][to])
+						}
+						break
+					}
+				}
+				cycle = append(cycle, packagePath(d))
+				return fmt.Errorf("import cycle between packages %s (%s)", strings.Join(cycle, " -> "), strings.Join(reasons, ", "))
+			case unvisited:
+				if err := visit(d); err != nil {
+					return err
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[p] = visited
+		return nil
+	}
+
+	for _, p := range pkgs {
+		if state[p] == unvisited {
+			if err := visit(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func packagePath(p *PackageMapping) string {
+	if p == nil {
+		return "<default>"
+	}
+	return p.ImportPath
+}
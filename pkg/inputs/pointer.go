@@ -0,0 +1,101 @@
+package inputs
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Pointer is a JSON pointer as defined by RFC 6901, held as its unescaped reference tokens, e.g.
+// ["properties", "a/b"] for "/properties/a~1b".
+type Pointer []string
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// ParsePointer parses the string form of a JSON pointer, e.g. "/properties/a~1b". The fragment of a URI is
+// percent-decoded before it is parsed, which url.Parse does.
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer \"%s\": it must be empty or start with /", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		for j := strings.Index(token, "~"); j >= 0; j = strings.Index(token, "~") {
+			if j == len(token)-1 || (token[j+1] != '0' && token[j+1] != '1') {
+				return nil, fmt.Errorf("invalid JSON pointer \"%s\": ~ must be followed by 0 or 1", s)
+			}
+			token = token[j+2:]
+		}
+		tokens[i] = pointerUnescaper.Replace(tokens[i])
+	}
+	return tokens, nil
+}
+
+// EscapePointerToken escapes a reference token of a JSON pointer, e.g. "a/b" => "a~1b".
+func EscapePointerToken(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+// String returns the string form of the pointer, e.g. "/properties/a~1b".
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteByte('/')
+		b.WriteString(EscapePointerToken(token))
+	}
+	return b.String()
+}
+
+// Fragment returns the pointer as the fragment of a URI, percent-encoding the characters which are not allowed in
+// fragments, e.g. "#/properties/a%20b".
+func (p Pointer) Fragment() string {
+	return "#" + (&url.URL{Fragment: p.String()}).EscapedFragment()
+}
+
+// Append returns the pointer extended by the given unescaped tokens.
+func (p Pointer) Append(tokens ...string) Pointer {
+	return append(append(Pointer{}, p...), tokens...)
+}
+
+// Resolve returns the subschema of schema the pointer refers to, e.g. the property "name" for
+// "/properties/name". The empty pointer refers to schema itself.
+func (p Pointer) Resolve(schema *Schema) (*Schema, error) {
+	for i := 0; i < len(p); {
+		next, n := schema.subschema(p[i:])
+		if next == nil {
+			return nil, fmt.Errorf("no schema at \"%s\"", p[:i+1])
+		}
+		schema = next
+		i += n
+	}
+	return schema, nil
+}
+
+// subschema returns the subschema tokens start with and the number of tokens its location takes, e.g. two for
+// ["properties", "name"], one for ["items"] and three for ["components", "schemas", "Pet"].
+func (schema *Schema) subschema(tokens []string) (*Schema, int) {
+	for _, sub := range schema.Subschemas() {
+		if prefix := sub.tokens(); hasPrefix(tokens, prefix) {
+			return sub.Schema, len(prefix)
+		}
+	}
+	return nil, 0
+}
+
+func hasPrefix(tokens, prefix []string) bool {
+	if len(prefix) > len(tokens) {
+		return false
+	}
+	for i := range prefix {
+		if tokens[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
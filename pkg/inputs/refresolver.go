@@ -4,14 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 )
 
-// RefResolver allows references to be resolved.
+// RefResolver allows references to be resolved. It knows the documents and the schemas identified by an $id, e.g.
+// "https://schemas.example.com/common.json" or "common.json#address", and resolves JSON pointer fragments by
+// walking the schema they are relative to.
 type RefResolver struct {
 	schemas []*Schema
-	//           k=uri     v=Schema
-	pathToSchema map[string]*Schema
+	//         k=uri     v=Schema
+	uriToSchema map[string]*Schema
 }
 
 // NewRefResolver creates a reference resolver.
@@ -23,15 +26,37 @@ func NewRefResolver(schemas []*Schema) *RefResolver {
 
 // Init the resolver.
 func (r *RefResolver) Init() error {
-	r.pathToSchema = make(map[string]*Schema)
+	r.uriToSchema = make(map[string]*Schema)
 	for _, v := range r.schemas {
-		if err := r.mapPaths(v); err != nil {
+		if err := r.mapIDs(v); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// add a document loaded for a reference which resolved to uri. The document is also identified by uri when its $id
+// differs, e.g. when it was found on a search path.
+func (r *RefResolver) add(schema *Schema, uri *url.URL) error {
+	r.schemas = append(r.schemas, schema)
+	if err := r.mapIDs(schema); err != nil {
+		return err
+	}
+	if r.known(uri) {
+		return nil
+	}
+	if err := r.InsertURI(uriKey(uri), schema); err != nil {
+		return err
+	}
+	return r.updateURIs(schema, *uri)
+}
+
+// known reports whether uri identifies a schema.
+func (r *RefResolver) known(uri *url.URL) bool {
+	_, ok := r.uriToSchema[uriKey(uri)]
+	return ok
+}
+
 // recusively generate path to schema
 func getPath(schema *Schema, path string) string {
 	path = schema.PathElement + "/" + path
@@ -59,22 +84,42 @@ func (r *RefResolver) GetSchemaByReference(schema *Schema) (*Schema, error) {
 	if err != nil {
 		return nil, err
 	}
-	resolvedPath := u.ResolveReference(ref)
-	path, ok := r.pathToSchema[resolvedPath.String()]
+	resolved, err := r.resolve(u.ResolveReference(ref))
+	if err != nil {
+		return nil, errors.New("refresolver.GetSchemaByReference: reference not found: " + schema.Reference + ": " + err.Error())
+	}
+	return resolved, nil
+}
+
+// resolve returns the schema identified by uri. A plain name fragment, e.g. "#address", identifies a schema by its
+// $id, while a JSON pointer fragment, e.g. "#/definitions/address", is resolved from the schema identified by the
+// URI without the fragment.
+func (r *RefResolver) resolve(uri *url.URL) (*Schema, error) {
+	if uri.Fragment != "" && !strings.HasPrefix(uri.Fragment, "/") {
+		if schema, ok := r.uriToSchema[uriKey(uri)]; ok {
+			return schema, nil
+		}
+		return nil, fmt.Errorf("no schema with the $id %s", uri)
+	}
+
+	base := *uri
+	base.Fragment = ""
+	base.RawFragment = ""
+	schema, ok := r.uriToSchema[uriKey(&base)]
 	if !ok {
-		return nil, errors.New("refresolver.GetSchemaByReference: reference not found: " + schema.Reference)
+		return nil, fmt.Errorf("no document %s", &base)
+	}
+	pointer, err := ParsePointer(uri.Fragment)
+	if err != nil {
+		return nil, err
 	}
-	return path, nil
+	return pointer.Resolve(schema)
 }
 
-func (r *RefResolver) mapPaths(schema *Schema) error {
+// mapIDs identifies the document schema by its $id, and the subschemas by theirs.
+func (r *RefResolver) mapIDs(schema *Schema) error {
 	rootURI := &url.URL{}
-	id := schema.ID()
-	if id == "" {
-		if err := r.InsertURI("#", schema); err != nil {
-			return err
-		}
-	} else {
+	if id := schema.ID(); id != "" {
 		var err error
 		rootURI, err = url.Parse(id)
 		if err != nil {
@@ -82,91 +127,72 @@ func (r *RefResolver) mapPaths(schema *Schema) error {
 		}
 		// ensure no fragment.
 		rootURI.Fragment = ""
-		if err := r.InsertURI(rootURI.String(), schema); err != nil {
-			return err
-		}
-		// add as JSON pointer (?)
-		if err := r.InsertURI(rootURI.String()+"#", schema); err != nil {
-			return err
-		}
+		rootURI.RawFragment = ""
 	}
-
-	return r.updateURIs(schema, *rootURI, false, false)
+	if err := r.InsertURI(uriKey(rootURI), schema); err != nil {
+		return err
+	}
+	return r.updateURIs(schema, *rootURI)
 }
 
-// create a map of base URIs
-func (r *RefResolver) updateURIs(schema *Schema, baseURI url.URL, checkCurrentID bool, ignoreFragments bool) error {
-	// already done for root, and if schema sets a new base URI
-	if checkCurrentID {
-		id := schema.ID()
-		if id != "" {
-			newBase, err := url.Parse(id)
+// updateURIs identifies the subschemas of schema with an $id, which is resolved against baseURI. An $id without a
+// fragment sets the base URI of the subschemas nested in it, a plain name fragment only identifies the schema.
+func (r *RefResolver) updateURIs(schema *Schema, baseURI url.URL) error {
+	for _, sub := range schema.Subschemas() {
+		subBase := baseURI
+		if id := sub.Schema.ID(); id != "" {
+			u, err := url.Parse(id)
 			if err != nil {
 				return err
 			}
-			// if it's a JSON fragment and we're coming from part of the tree where the baseURI has changed, we need to
-			// ignore the fragment, since it won't be resolvable under the current baseURI.
-			if !(strings.HasPrefix(id, "#") && ignoreFragments) {
-				// map all the subschema under the new base
-				resolved := baseURI.ResolveReference(newBase)
-				if err := r.InsertURI(resolved.String(), schema); err != nil {
-					return err
-				}
-				if resolved.Fragment == "" {
-					if err := r.InsertURI(resolved.String()+"#", schema); err != nil {
-						return err
-					}
-				}
-				if err := r.updateURIs(schema, *resolved, false, false); err != nil {
-					return err
-				}
-				// and continue to map all subschema under the old base (except for fragments)
-				ignoreFragments = true
+			resolved := baseURI.ResolveReference(u)
+			if err := r.InsertURI(uriKey(resolved), sub.Schema); err != nil {
+				return err
+			}
+			if resolved.Fragment == "" {
+				subBase = *resolved
 			}
 		}
-	}
-	for k, subSchema := range schema.Definitions {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/definitions/" + k
-		if err := r.InsertURI(newBaseURI.String(), subSchema); err != nil {
-			return err
-		}
-		if err := r.updateURIs(subSchema, newBaseURI, true, ignoreFragments); err != nil {
-			return err
-		}
-	}
-	for k, subSchema := range schema.Properties {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/properties/" + k
-		if err := r.InsertURI(newBaseURI.String(), subSchema); err != nil {
-			return err
-		}
-		if err := r.updateURIs(subSchema, newBaseURI, true, ignoreFragments); err != nil {
+		if err := r.updateURIs(sub.Schema, subBase); err != nil {
 			return err
 		}
 	}
-	if schema.AdditionalProperties != nil {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/additionalProperties"
-		if err := r.updateURIs((*Schema)(schema.AdditionalProperties), newBaseURI, true, ignoreFragments); err != nil {
-			return err
-		}
+	return nil
+}
+
+// locations returns every schema under the URIs it can be referenced by, the URIs identifying schemas followed by
+// the JSON pointers of their subschemas, e.g. "common.json#" and "common.json#/definitions/money".
+func (r *RefResolver) locations() map[string]*Schema {
+	locations := map[string]*Schema{}
+	keys := make([]string, 0, len(r.uriToSchema))
+	for k := range r.uriToSchema {
+		keys = append(keys, k)
 	}
-	if schema.Items != nil {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/items"
-		if err := r.updateURIs(schema.Items, newBaseURI, true, ignoreFragments); err != nil {
-			return err
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.Contains(k, "#") {
+			// a plain name fragment
+			locations[k] = r.uriToSchema[k]
+			continue
 		}
+		r.uriToSchema[k].Walk(func(pointer string, schema *Schema) error {
+			locations[k+"#"+pointer] = schema
+			return nil
+		})
 	}
-	return nil
+	return locations
+}
+
+// uriKey returns the key of uri in the resolver, which has no trailing "#" for an empty fragment.
+func uriKey(uri *url.URL) string {
+	return strings.TrimSuffix(uri.String(), "#")
 }
 
 // InsertURI to the references.
 func (r *RefResolver) InsertURI(uri string, schema *Schema) error {
-	if _, ok := r.pathToSchema[uri]; ok {
+	if _, ok := r.uriToSchema[uri]; ok {
 		return fmt.Errorf("attempted to add duplicate uri: %s/%s", schema.GetRoot().ID(), uri)
 	}
-	r.pathToSchema[uri] = schema
+	r.uriToSchema[uri] = schema
 	return nil
 }
@@ -0,0 +1,222 @@
+package inputs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NamingConvention controls how a JSON property name is rendered in a struct tag.
+type NamingConvention string
+
+const (
+	// NamingAsIs uses the JSON property name unchanged, e.g. "houseNumber".
+	NamingAsIs NamingConvention = "as-is"
+	// NamingCamel renders the property name in camel case, e.g. "houseNumber".
+	NamingCamel NamingConvention = "camel"
+	// NamingSnake renders the property name in snake case, e.g. "house_number".
+	NamingSnake NamingConvention = "snake"
+	// NamingKebab renders the property name in kebab case, e.g. "house-number".
+	NamingKebab NamingConvention = "kebab"
+)
+
+// TagOption describes a struct tag which is emitted alongside the json tag, e.g. yaml or bson.
+type TagOption struct {
+	// Name of the tag key, e.g. "yaml".
+	Name string
+	// Naming is the convention used to render the property name in the tag.
+	Naming NamingConvention
+}
+
+// omitEmptyTags lists the tag keys which understand the ",omitempty" option.
+var omitEmptyTags = map[string]bool{
+	"json":         true,
+	"yaml":         true,
+	"bson":         true,
+	"mapstructure": true,
+	"toml":         true,
+	"msgpack":      true,
+}
+
+// ParseTagOptions parses a comma separated list of tags with an optional naming convention,
+// e.g. "yaml:snake,bson:camel,db". A validate tag is only written with the value set by x-go-tags, since a property
+// name isn't a validator.
+func ParseTagOptions(s string) ([]TagOption, error) {
+	var tags []TagOption
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		tag := TagOption{Name: item, Naming: NamingAsIs}
+		if idx := strings.Index(item, ":"); idx >= 0 {
+			tag.Name = item[:idx]
+			tag.Naming = NamingConvention(item[idx+1:])
+		}
+
+		switch tag.Naming {
+		case NamingAsIs, NamingCamel, NamingSnake, NamingKebab:
+		default:
+			return nil, fmt.Errorf("unknown naming convention '%s' for tag '%s', expected one of %s, %s, %s or %s",
+				tag.Naming, tag.Name, NamingAsIs, NamingCamel, NamingSnake, NamingKebab)
+		}
+		if tag.Name == "" || tag.Name == "json" {
+			return nil, fmt.Errorf("invalid tag '%s'", item)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// ApplyNamingConvention renders a JSON property name using the naming convention.
+func ApplyNamingConvention(name string, naming NamingConvention) string {
+	switch naming {
+	case NamingCamel:
+		words := splitWords(name)
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				w = CapitaliseFirstLetter(w)
+			}
+			words[i] = w
+		}
+		return strings.Join(words, "")
+	case NamingSnake:
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	case NamingKebab:
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	}
+	return name
+}
+
+// splitWords splits a property name into words on separators and case changes,
+// e.g. "houseNumber" => ["house", "Number"] and "HTTPServer" => ["HTTP", "Server"].
+func splitWords(s string) []string {
+	var words []string
+	for _, part := range splitOnAll(s, IsNotAGoNameCharacter) {
+		if part == "" {
+			continue
+		}
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower)) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
+
+// checkGoTags ensures the x-go-tags of the property schema can be written into a struct tag. The json tag can't be
+// overridden, except on embedded fields, since the generated methods read and write the property by its name.
+func checkGoTags(schema *Schema) error {
+	for key, value := range schema.GoTags {
+		if key == "json" && !schema.GoEmbed {
+			return fmt.Errorf("x-go-tags at \"%s\" can't set the json tag, which is the property name; set x-go-name to rename the field", schemaLocation(schema))
+		}
+		if key == "" || strings.IndexFunc(key, func(r rune) bool { return r <= ' ' || r == ':' || r == '"' || r == '`' || r == 0x7f }) >= 0 {
+			return fmt.Errorf("x-go-tags at \"%s\": invalid tag name %q", schemaLocation(schema), key)
+		}
+		if strings.Contains(value, "`") {
+			return fmt.Errorf("x-go-tags at \"%s\": the value of the %s tag can't contain a backquote", schemaLocation(schema), key)
+		}
+	}
+	return nil
+}
+
+// checkTagOptions ensures the configured tags don't conflict with the tags derived from the schema.
+func checkTagOptions(opts OutputOptions) error {
+	for _, t := range opts.Tags {
+		if t.Name == "validate" && opts.ValidateTags {
+			return errors.New("the tag 'validate' of Tags (-tags) conflicts with ValidateTags (-validate-tags), which derives the validate tags from the schema constraints, use one or the other")
+		}
+	}
+	return nil
+}
+
+// fieldTag returns the struct tag for a field, without the surrounding backticks. Generated holds tag values
+// derived from the schema, e.g. validate, which are used unless the property overrides them.
+func fieldTag(f *Field, opts OutputOptions, generated map[string]string) string {
+	omitempty := ""
+	if !f.Required {
+		omitempty = ",omitempty"
+	}
+	if f.OmitEmpty != nil {
+		omitempty = ""
+		if *f.OmitEmpty {
+			omitempty = ",omitempty"
+		}
+	}
+
+	tagValue := func(key string, name string) string {
+		if value, ok := f.Tags[key]; ok {
+			return value
+		}
+		if value, ok := generated[key]; ok {
+			return value
+		}
+		if name == "-" {
+			return name
+		}
+		if omitEmptyTags[key] {
+			return name + omitempty
+		}
+		return name
+	}
+
+	tags := []string{"json:" + strconv.Quote(tagValue("json", f.JSONName))}
+	written := map[string]bool{"json": true}
+	for _, t := range opts.Tags {
+		if written[t.Name] {
+			continue
+		}
+		if _, ok := f.Tags[t.Name]; !ok && t.Name == "validate" {
+			continue
+		}
+		written[t.Name] = true
+		tags = append(tags, t.Name+":"+strconv.Quote(tagValue(t.Name, ApplyNamingConvention(f.JSONName, t.Naming))))
+	}
+
+	// tags which are only derived from or set on the property through the schema
+	var extra []string
+	for _, m := range []map[string]string{generated, f.Tags} {
+		for k := range m {
+			if !written[k] {
+				written[k] = true
+				extra = append(extra, k)
+			}
+		}
+	}
+	sort.Strings(extra)
+	for _, k := range extra {
+		tags = append(tags, k+":"+strconv.Quote(tagValue(k, f.JSONName)))
+	}
+
+	return strings.Join(tags, " ")
+}
+
+// embeddedFieldTag returns the struct tag for an embedded field, which only carries the tags set through the schema.
+func embeddedFieldTag(f *Field) string {
+	keys := make([]string, 0, len(f.Tags))
+	for k := range f.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tags := make([]string, len(keys))
+	for i, k := range keys {
+		tags[i] = k + ":" + strconv.Quote(f.Tags[k])
+	}
+	return strings.Join(tags, " ")
+}
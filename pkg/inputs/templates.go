@@ -0,0 +1,331 @@
+package inputs
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// The named templates code is generated with. Each can be overridden by a file called <name>.tmpl in
+// OutputOptions.TemplateDir.
+const (
+	// StructTemplate renders the declaration of a struct, given a StructData.
+	StructTemplate = "struct"
+	// AliasTemplate renders the declaration of an alias, given an AliasData.
+	AliasTemplate = "alias"
+	// MarshalTemplate renders the MarshalJSON method of a struct, given a StructData.
+	MarshalTemplate = "marshal"
+	// UnmarshalTemplate renders the UnmarshalJSON method of a struct, given a StructData.
+	UnmarshalTemplate = "unmarshal"
+	// ValidateTemplate renders the Validate method of a struct, given a StructData.
+	ValidateTemplate = "validate"
+	// HelpersTemplate renders the declarations shared by the generated methods, such as ErrFieldRequired.
+	HelpersTemplate = "helpers"
+	// RegistryTemplate renders the registry of the types of the payloads of named messages, given a RegistryData.
+	RegistryTemplate = "registry"
+	// UnionTemplate renders the MarshalJSON, UnmarshalJSON and Validate methods of a union, given a StructData whose
+	// Union is set.
+	UnionTemplate = "union"
+)
+
+// RuntimeImportPath is the package code generated with OutputOptions.Runtime calls.
+const RuntimeImportPath = "github.com/brenank/json-schema-to-go-struct-generator/pkg/schemaruntime"
+
+//go:embed templates/*.tmpl templates/runtime/*.tmpl
+var defaultTemplateFiles embed.FS
+
+// defaultTemplates are parsed once; each output clones them to bind its own imports.
+var defaultTemplates = template.Must(parseTemplateFiles(template.New("").Funcs(templateFuncs(nil)), defaultTemplateFiles, "templates/*.tmpl"))
+
+// runtimeTemplates replace the methods and helpers with calls to the runtime package.
+var runtimeTemplates = template.Must(parseTemplateFiles(template.Must(defaultTemplates.Clone()), defaultTemplateFiles, "templates/runtime/*.tmpl"))
+
+// StructData is the data passed to the struct, marshal, unmarshal and validate templates.
+type StructData struct {
+	// Struct the data is derived from.
+	Struct *Struct
+	// Name of the go type.
+	Name string
+	// Description of the struct.
+	Description string
+	// Fields in output order, including fields like AdditionalProperties which have no JSON name ("-").
+	Fields []FieldData
+	// EmbeddedNames are the JSON properties read by embedded types.
+	EmbeddedNames []string
+	// NeedValue is set when UnmarshalJSON reads the value of any property.
+	NeedValue bool
+	// NoAdditional is set when additional properties are not allowed.
+	NoAdditional bool
+	// AdditionalType is the go type of additional properties, empty when they are not read.
+	AdditionalType string
+	// MarshalAdditional is set when MarshalJSON writes the AdditionalProperties map.
+	MarshalAdditional bool
+	// Union is set when the struct holds one of the variants of a discriminator.
+	Union *UnionData
+}
+
+// UnionData is the data of a union, which the union template renders the methods of.
+type UnionData struct {
+	// Tag is the name of the go field holding the discriminator.
+	Tag string
+	// TagJSONName is the name of the discriminator property.
+	TagJSONName string
+	// TagKey is a go string literal of the start of the JSON object up to the value of the tag, e.g. `"{\"type\":"`.
+	TagKey string
+	// Variants in the order of their values.
+	Variants []VariantData
+	// Inline is set when the variants read and write the discriminator property themselves.
+	Inline bool
+}
+
+// VariantData is the data of a variant of a union.
+type VariantData struct {
+	// Value of the tag selecting the variant.
+	Value string
+	// Name of the go field holding the variant.
+	Name string
+	// Type is the go type of the variant, without the pointer of structs.
+	Type string
+}
+
+// FieldData is the data of a struct field.
+type FieldData struct {
+	// Field the data is derived from.
+	Field *Field
+	// Name of the go field.
+	Name string
+	// JSONName of the property, "-" when the field is not a property.
+	JSONName string
+	// Type is the go type of the field.
+	Type string
+	// Tag is the struct tag, without backquotes.
+	Tag string
+	// Description of the field, lines separated by newlines.
+	Description string
+	// Required is set when the property is required.
+	Required bool
+	// Embedded is set when the type is embedded in the struct.
+	Embedded bool
+	// AccessName is the name the field is accessed by, i.e. the type name of an embedded field.
+	AccessName string
+	// NilCheck is set when MarshalJSON checks that a required field is not nil.
+	NilCheck bool
+	// OmitEmpty is the condition under which MarshalJSON writes a field which x-go-omitempty leaves out while it is
+	// empty, e.g. `strct.Note != ""`, or empty when the field is always written.
+	OmitEmpty string
+	// Debug annotates the field when OutputOptions.Debug is set.
+	Debug string
+}
+
+// AliasData is the data passed to the alias template.
+type AliasData struct {
+	// Alias the data is derived from.
+	Alias *Field
+	// Name of the go type.
+	Name string
+	// Type is the go type aliased.
+	Type string
+}
+
+// RegistryData is the data passed to the registry template.
+type RegistryData struct {
+	// Messages ordered by name.
+	Messages []MessageData
+}
+
+// MessageData is the data of a message in the registry.
+type MessageData struct {
+	// Message the data is derived from.
+	Message *MessageType
+	// Name of the message.
+	Name string
+	// Type is the go type of the payload, without the pointer of structs.
+	Type string
+}
+
+// templateFuncs are the functions available in templates. import registers an import path used by the rendered code.
+func templateFuncs(imports map[string]bool) template.FuncMap {
+	return template.FuncMap{
+		"import": func(path string) string {
+			if imports != nil {
+				imports[path] = true
+			}
+			return ""
+		},
+		"runtimeImport": func() string {
+			return RuntimeImportPath
+		},
+		"comment": func(s string) string {
+			return strings.Join(strings.Split(s, "\n"), "\n// ")
+		},
+	}
+}
+
+// loadTemplates returns the templates to render with, registering imports of rendered code in imports.
+func loadTemplates(opts OutputOptions, imports map[string]bool) (*template.Template, error) {
+	base := defaultTemplates
+	if opts.Runtime {
+		base = runtimeTemplates
+	}
+	t, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	t.Funcs(templateFuncs(imports))
+	if opts.TemplateDir == "" {
+		return t, nil
+	}
+	return parseTemplateFiles(t, os.DirFS(opts.TemplateDir), "*.tmpl")
+}
+
+// parseTemplateFiles parses each <name>.tmpl file matching pattern as the template called name, replacing any
+// existing definition.
+func parseTemplateFiles(t *template.Template, fsys fs.FS, pattern string) (*template.Template, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error listing templates: %v", err)
+	}
+	for _, match := range matches {
+		content, err := fs.ReadFile(fsys, match)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template %s: %v", match, err)
+		}
+		if _, err := t.New(strings.TrimSuffix(path.Base(match), ".tmpl")).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("error parsing template %s: %v", match, err)
+		}
+	}
+	return t, nil
+}
+
+// nonEmpty returns the condition under which the field at access of the go type typeName isn't empty in the sense of
+// encoding/json's omitempty, or an empty string for types which are never empty, like structs.
+func nonEmpty(access string, typeName string) string {
+	switch {
+	case typeName == "string":
+		return access + ` != ""`
+	case typeName == "bool":
+		return access
+	case typeName == "int" || typeName == "float64":
+		return access + " != 0"
+	case strings.HasPrefix(typeName, "*") || typeName == "interface{}":
+		return access + " != nil"
+	case strings.HasPrefix(typeName, "[]") || strings.HasPrefix(typeName, "map["):
+		return "len(" + access + ") > 0"
+	}
+	return ""
+}
+
+// executeTemplate renders the template name, followed by a blank line.
+func executeTemplate(w io.Writer, t *template.Template, name string, data interface{}) error {
+	if err := t.ExecuteTemplate(w, name, data); err != nil {
+		return fmt.Errorf("error executing template %s: %v", name, err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// newStructData prepares the template data of s in the package pkg.
+func newStructData(g *Generator, s *Struct, pkg *packageContext, opts OutputOptions) (*StructData, error) {
+	d := &StructData{
+		Struct:        s,
+		Name:          s.TypeInfo.String(),
+		Description:   s.Description,
+		EmbeddedNames: embeddedJSONNames(g, s),
+	}
+
+	for _, fieldKey := range s.OrderedFieldNames(opts.AlphabeticalFields) {
+		f := s.Fields[fieldKey]
+
+		typeName, err := fieldTypeName(f, pkg)
+		if err != nil {
+			return nil, err
+		}
+		fd := FieldData{
+			Field:       f,
+			Name:        f.Name,
+			JSONName:    f.JSONName,
+			Type:        typeName,
+			Description: strings.Join(f.Descriptions, "\n"),
+			Required:    f.Required,
+			Embedded:    f.Embedded,
+			AccessName:  f.Name,
+			// currently only objects are supported
+			NilCheck: f.Required && strings.HasPrefix(f.Type.Name, "*"),
+		}
+		if f.OmitEmpty != nil && *f.OmitEmpty {
+			fd.OmitEmpty = nonEmpty("strct."+f.Name, typeName)
+		}
+		if opts.Debug {
+			fd.Debug = fmt.Sprintf("s:%s, f:%s", f.Type.Id, f.Id)
+		}
+
+		if f.Embedded {
+			if fd.AccessName, err = embeddedFieldName(typeName); err != nil {
+				return nil, fmt.Errorf("cannot embed field %s of %s: %v", f.Name, s.TypeInfo, err)
+			}
+			// the json tag is left off so the fields of the embedded type are flattened
+			if len(f.Tags) > 0 {
+				fd.Tag = embeddedFieldTag(f)
+			}
+		} else {
+			generatedTags := map[string]string{}
+			if _, overridden := f.Tags["validate"]; opts.ValidateTags && !overridden && f.JSONName != "-" {
+				tag, warnings := g.validateTag(s.TypeInfo.String(), f, typeName)
+				for _, warning := range warnings {
+					opts.warnf("%s", warning)
+				}
+				if tag != "" {
+					generatedTags["validate"] = tag
+				}
+			}
+			fd.Tag = fieldTag(f, opts, generatedTags)
+			if f.JSONName != "-" {
+				d.NeedValue = true
+			}
+		}
+		d.Fields = append(d.Fields, fd)
+	}
+
+	if at := s.AdditionalType; at != nil {
+		if at.PrimitiveType == "boolean" && at.Name == "false" {
+			// all unknown properties are not allowed
+			d.NoAdditional = true
+		} else {
+			pt, err := at.goTypeName(pkg)
+			if err != nil {
+				return nil, fmt.Errorf("cannot type the additional properties of %s: %v", s.TypeInfo, err)
+			}
+			d.AdditionalType = pt
+			d.NeedValue = true
+		}
+		d.MarshalAdditional = at.PrimitiveType != "boolean" && at.Name != "false"
+	}
+
+	if u := s.Union; u != nil {
+		key, err := json.Marshal(u.Tag.JSONName)
+		if err != nil {
+			return nil, err
+		}
+		d.Union = &UnionData{
+			Tag:         u.Tag.Name,
+			TagJSONName: u.Tag.JSONName,
+			TagKey:      strconv.Quote("{" + string(key) + ":"),
+			Inline:      u.Inline,
+		}
+		for _, v := range u.Variants {
+			typeName, err := fieldTypeName(v.Field, pkg)
+			if err != nil {
+				return nil, err
+			}
+			d.Union.Variants = append(d.Union.Variants, VariantData{Value: v.Value, Name: v.Field.Name, Type: strings.TrimPrefix(typeName, "*")})
+		}
+	}
+	return d, nil
+}
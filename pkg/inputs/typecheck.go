@@ -0,0 +1,248 @@
+package inputs
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxTypeErrors limits the number of compile errors reported by TypeCheck.
+const maxTypeErrors = 10
+
+// GeneratedPackage is the generated code of one go package.
+type GeneratedPackage struct {
+	// Mapping of the package, nil for the default package.
+	Mapping *PackageMapping
+	// Name of the go package.
+	Name string
+	// Dir is the directory the package is written to, imports are resolved from there.
+	Dir string
+	// Files of the package.
+	Files []File
+}
+
+// TypeCheck type-checks the generated packages with go/types without writing them. Compile errors are reported
+// against the schema locations that produced the offending types.
+func TypeCheck(g *Generator, pkgs []GeneratedPackage) error {
+	c := &typeChecker{
+		g:       g,
+		fset:    token.NewFileSet(),
+		pkgs:    map[string]*GeneratedPackage{},
+		checked: map[string]*types.Package{},
+		std:     importer.Default(),
+		source:  importer.ForCompiler(token.NewFileSet(), "source", nil).(types.ImporterFrom),
+	}
+	for i := range pkgs {
+		if pkgs[i].Mapping != nil {
+			c.pkgs[pkgs[i].Mapping.ImportPath] = &pkgs[i]
+		}
+	}
+
+	// mapped packages never import the default package, so checking it last resolves all generated imports
+	var errs []string
+	for i := range pkgs {
+		if pkgs[i].Mapping == nil {
+			continue
+		}
+		if _, err := c.check(&pkgs[i], nil); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	for i := range pkgs {
+		if pkgs[i].Mapping != nil {
+			continue
+		}
+		if _, err := c.check(&pkgs[i], nil); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("generated code does not compile:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+type typeChecker struct {
+	g       *Generator
+	fset    *token.FileSet
+	pkgs    map[string]*GeneratedPackage
+	checked map[string]*types.Package
+	errors  map[string]error
+	std     types.Importer
+	source  types.ImporterFrom
+}
+
+// check type-checks a generated package once, checking the generated packages it imports first.
+func (c *typeChecker) check(pkg *GeneratedPackage, importing []string) (*types.Package, error) {
+	path := pkg.Name
+	if pkg.Mapping != nil {
+		path = pkg.Mapping.ImportPath
+	}
+	if p, ok := c.checked[path]; ok {
+		return p, nil
+	}
+	if err, ok := c.errors[path]; ok {
+		return nil, err
+	}
+	for _, p := range importing {
+		if p == path {
+			return nil, fmt.Errorf("import cycle between generated packages %s", strings.Join(append(importing, path), " -> "))
+		}
+	}
+
+	var files []*ast.File
+	for _, f := range pkg.Files {
+		file, err := parser.ParseFile(c.fset, filepath.Join(pkg.Dir, f.Name), f.Content, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	var errs []string
+	conf := types.Config{
+		Importer: importerFunc(func(importPath string) (*types.Package, error) {
+			if dep, ok := c.pkgs[importPath]; ok {
+				return c.check(dep, append(importing, path))
+			}
+			if isStandardImport(importPath) {
+				return c.std.Import(importPath)
+			}
+			return c.source.ImportFrom(importPath, existingDir(pkg.Dir), 0)
+		}),
+		Error: func(err error) {
+			if len(errs) < maxTypeErrors {
+				errs = append(errs, c.describe(pkg, files, err))
+			}
+		},
+	}
+	checked, _ := conf.Check(path, c.fset, files, nil)
+	if len(errs) > 0 {
+		err := fmt.Errorf("%s", strings.Join(errs, "\n"))
+		if c.errors == nil {
+			c.errors = map[string]error{}
+		}
+		c.errors[path] = err
+		return nil, err
+	}
+	c.checked[path] = checked
+	return checked, nil
+}
+
+// describe formats a compile error with the schema location of the declaration containing it.
+func (c *typeChecker) describe(pkg *GeneratedPackage, files []*ast.File, err error) string {
+	terr, ok := err.(types.Error)
+	if !ok {
+		return err.Error()
+	}
+
+	msg := fmt.Sprintf("%s: %s", terr.Fset.Position(terr.Pos), terr.Msg)
+	name := declaredTypeName(files, terr.Pos)
+	if name == "" {
+		return msg
+	}
+	if location := c.g.typeLocation(pkg.Mapping, name); location != "" {
+		return fmt.Sprintf("%s (type %s generated from %s)", msg, name, location)
+	}
+	return fmt.Sprintf("%s (type %s)", msg, name)
+}
+
+// declaredTypeName returns the type declared by, or receiving, the top level declaration containing pos.
+func declaredTypeName(files []*ast.File, pos token.Pos) string {
+	for _, file := range files {
+		if pos < file.Pos() || pos > file.End() {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if pos < decl.Pos() || pos > decl.End() {
+				continue
+			}
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok && pos >= ts.Pos() && pos <= ts.End() {
+						return ts.Name.Name
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil && len(d.Recv.List) > 0 {
+					recv := d.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if ident, ok := recv.(*ast.Ident); ok {
+						return ident.Name
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// typeLocation returns the location of the schema the struct or alias name of a package was generated from.
+func (g *Generator) typeLocation(mapping *PackageMapping, name string) string {
+	key := qualifiedKey(mapping, name)
+	if s, ok := g.Structs[key]; ok && s.Schema != nil {
+		return schemaLocation(s.Schema)
+	}
+	if a, ok := g.Aliases[key]; ok && a.Schema != nil {
+		return schemaLocation(a.Schema)
+	}
+
+	// struct names are not always the keys they are stored under
+	keys := make([]string, 0, len(g.Structs))
+	for k := range g.Structs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := g.Structs[k]
+		if s.TypeInfo.Package == mapping && s.TypeInfo.String() == name && s.Schema != nil {
+			return schemaLocation(s.Schema)
+		}
+	}
+	return ""
+}
+
+// schemaLocation returns the URI of the document containing schema with the JSON pointer of schema as fragment,
+// e.g. "file:///schemas/order.json#/definitions/line".
+func schemaLocation(schema *Schema) string {
+	source := sourceOf(schema)
+	if schema.IsRoot() {
+		return source
+	}
+	return strings.SplitN(source, "#", 2)[0] + getPath(schema.Parent, schema.PathElement)
+}
+
+// existingDir returns dir, or its closest ancestor which exists, so imports can be resolved before the generated
+// code is written.
+func existingDir(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for {
+		if stat, err := os.Stat(dir); err == nil && stat.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
@@ -0,0 +1,168 @@
+package inputs
+
+import (
+	"fmt"
+	"go/token"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ParseTypeMap parses a comma separated list of schema URI to go type bindings,
+// e.g. "common.json#/definitions/money=github.com/acme/money.Amount,geo.json=*github.com/acme/geo.Point".
+func ParseTypeMap(s string) (map[string]string, error) {
+	typeMap := map[string]string{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(item, "=")
+		if idx <= 0 || idx == len(item)-1 {
+			return nil, fmt.Errorf("invalid type mapping '%s', expected <schema uri>=<go type>", item)
+		}
+		key, goType := item[:idx], item[idx+1:]
+		if _, ok := typeMap[key]; ok {
+			return nil, fmt.Errorf("duplicate type mapping for '%s'", key)
+		}
+		if _, _, err := ParseQualifiedType(goType); err != nil {
+			return nil, fmt.Errorf("invalid type mapping '%s': %v", item, err)
+		}
+		typeMap[key] = goType
+	}
+	return typeMap, nil
+}
+
+// ParseQualifiedType splits a package qualified go type, e.g. "*github.com/acme/money.Amount", into the type as it
+// is written in code, e.g. "*money.Amount", and its import path, e.g. "github.com/acme/money". Types without a
+// package, e.g. "string", have no import path. The package name is derived from the import path unless it follows
+// a semicolon, e.g. "github.com/acme/go-money;money.Amount", as it must when the last element of the import path
+// isn't the name of the package.
+func ParseQualifiedType(qualified string) (goType string, importPath string, err error) {
+	prefix := ""
+	name := qualified
+	for strings.HasPrefix(name, "*") || strings.HasPrefix(name, "[]") {
+		if strings.HasPrefix(name, "*") {
+			prefix += "*"
+			name = name[1:]
+		} else {
+			prefix += "[]"
+			name = name[2:]
+		}
+	}
+
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 || dot < strings.LastIndex(name, "/") {
+		return qualified, "", nil
+	}
+
+	importPath, typeName := name[:dot], name[dot+1:]
+	pkg := packageName(importPath)
+	if idx := strings.LastIndex(importPath, ";"); idx >= 0 {
+		importPath, pkg = importPath[:idx], importPath[idx+1:]
+		if !token.IsIdentifier(pkg) {
+			return "", "", fmt.Errorf("the package name \"%s\" of \"%s\" is not a go identifier", pkg, qualified)
+		}
+	} else if !token.IsIdentifier(pkg) {
+		return "", "", fmt.Errorf("the package name \"%s\" guessed from the import path \"%s\" is not a go identifier, name the package after a semicolon, e.g. \"%s;<name>.%s\"", pkg, importPath, importPath, typeName)
+	}
+	if importPath == "" || !token.IsIdentifier(typeName) {
+		return "", "", fmt.Errorf("invalid go type \"%s\", expected <import path>.<type> or <import path>;<package name>.<type>", qualified)
+	}
+	return prefix + pkg + "." + typeName, importPath, nil
+}
+
+// packageName guesses the package name of an import path from its last element, skipping major version suffixes,
+// e.g. "github.com/acme/money/v2" => "money" and "gopkg.in/yaml.v3" => "yaml".
+func packageName(importPath string) string {
+	elements := strings.Split(importPath, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && isMajorVersion(name) {
+		name = elements[len(elements)-2]
+	}
+	if idx := strings.LastIndex(name, "."); idx > 0 && isMajorVersion(name[idx+1:]) {
+		name = name[:idx]
+	}
+	return name
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// mapTypes binds the schemas matched by the type map keys to their go types. Keys which match no schema are an error,
+// since the type would be generated instead.
+func (g *Generator) mapTypes() error {
+	if len(g.options.TypeMap) == 0 {
+		return nil
+	}
+
+	locations := g.resolver.locations()
+	uris := make([]string, 0, len(locations))
+	for uri := range locations {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	// more specific (longer) keys take precedence
+	keys := make([]string, 0, len(g.options.TypeMap))
+	for k := range g.options.TypeMap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	var unmatched []string
+	for _, k := range keys {
+		if _, _, err := ParseQualifiedType(g.options.TypeMap[k]); err != nil {
+			return fmt.Errorf("invalid type mapping '%s': %v", k, err)
+		}
+		key := withFragment(k)
+		absolute := false
+		if u, err := url.Parse(k); err == nil && u.IsAbs() {
+			absolute = true
+		}
+
+		matched := false
+		for _, uri := range uris {
+			candidate := withFragment(uri)
+			if candidate != key && (absolute || !strings.HasSuffix(candidate, "/"+key)) {
+				continue
+			}
+			matched = true
+			schema := locations[uri]
+			if _, mapped := g.mappedTypes[schema]; !mapped {
+				g.mappedTypes[schema] = g.options.TypeMap[k]
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, "'"+k+"'")
+		}
+	}
+	if len(unmatched) > 0 {
+		sort.Strings(unmatched)
+		return fmt.Errorf("no schema matches the type mapping of %s", strings.Join(unmatched, ", "))
+	}
+	return nil
+}
+
+// withFragment ensures a URI ends in a fragment so "common.json" and "common.json#" compare equal.
+func withFragment(uri string) string {
+	if strings.Contains(uri, "#") {
+		return uri
+	}
+	return uri + "#"
+}
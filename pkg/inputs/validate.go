@@ -0,0 +1,154 @@
+package inputs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// validatorFormats maps JSON schema formats onto go-playground/validator tags.
+var validatorFormats = map[string]string{
+	"date":      "datetime=2006-01-02",
+	"date-time": "datetime=2006-01-02T15:04:05Z07:00",
+	"email":     "email",
+	"hostname":  "hostname_rfc1123",
+	"ipv4":      "ipv4",
+	"ipv6":      "ipv6",
+	"uri":       "uri",
+	"uuid":      "uuid",
+}
+
+// validateTag translates the constraints of the schema a field of the go type typeName was generated from into a
+// go-playground/validator tag, e.g. "required,min=1,max=40". Constraints which cannot be expressed as a tag are
+// returned as warnings.
+func (g *Generator) validateTag(structName string, f *Field, typeName string) (tag string, warnings []string) {
+	var rules []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf("validate tag for %s.%s: ", structName, f.Name)+fmt.Sprintf(format, args...))
+	}
+
+	schema := g.resolveConstraints(f.Schema)
+	if schema != nil {
+		schemaType, _ := schema.Type()
+
+		if schema.MinLength != nil {
+			rules = append(rules, fmt.Sprintf("min=%d", *schema.MinLength))
+		}
+		if schema.MaxLength != nil {
+			rules = append(rules, fmt.Sprintf("max=%d", *schema.MaxLength))
+		}
+
+		rules = append(rules, numericRules(schema)...)
+
+		if len(schema.Enum) > 0 {
+			if rule, ok := oneOfRule(schema.Enum); ok {
+				rules = append(rules, rule)
+			} else {
+				warn("enum %v cannot be expressed as a oneof rule", schema.Enum)
+			}
+		}
+
+		if schema.Pattern != "" {
+			warn("pattern %q cannot be expressed as a validator tag", schema.Pattern)
+		}
+
+		if schema.Format != "" && (schemaType == "string" || schemaType == "") {
+			if rule, ok := validatorFormats[schema.Format]; ok {
+				rules = append(rules, rule)
+			} else {
+				warn("format %q has no validator equivalent", schema.Format)
+			}
+		}
+	}
+
+	if f.Required {
+		// the validator rejects the zero value of a required field, which is only missing when the field is nil
+		if nillable(typeName) {
+			rules = append([]string{"required"}, rules...)
+		}
+	} else if len(rules) > 0 {
+		rules = append([]string{"omitempty"}, rules...)
+	}
+
+	return strings.Join(rules, ","), warnings
+}
+
+// nillable reports whether a field of the go type typeName is nil when its property is missing, rather than a zero
+// value which the property may hold, e.g. false or 0.
+func nillable(typeName string) bool {
+	for _, prefix := range []string{"*", "[]", "map[", "interface{}"} {
+		if strings.HasPrefix(typeName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveConstraints follows references to the schema which holds the constraints of a property.
+func (g *Generator) resolveConstraints(schema *Schema) *Schema {
+	seen := map[*Schema]bool{}
+	for schema != nil && schema.Reference != "" && !seen[schema] {
+		seen[schema] = true
+		ref, err := g.resolver.GetSchemaByReference(schema)
+		if err != nil {
+			return schema
+		}
+		schema = ref
+	}
+	return schema
+}
+
+func numericRules(schema *Schema) []string {
+	var rules []string
+	formatNumber := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	// up to draft-04 exclusiveMinimum and exclusiveMaximum are booleans modifying minimum and maximum
+	exclusiveMin, _ := schema.ExclusiveMinimum.(bool)
+	exclusiveMax, _ := schema.ExclusiveMaximum.(bool)
+
+	if schema.Minimum != nil {
+		if exclusiveMin {
+			rules = append(rules, "gt="+formatNumber(*schema.Minimum))
+		} else {
+			rules = append(rules, "gte="+formatNumber(*schema.Minimum))
+		}
+	}
+	if schema.Maximum != nil {
+		if exclusiveMax {
+			rules = append(rules, "lt="+formatNumber(*schema.Maximum))
+		} else {
+			rules = append(rules, "lte="+formatNumber(*schema.Maximum))
+		}
+	}
+
+	// from draft-06 onwards they are numbers
+	if v, ok := schema.ExclusiveMinimum.(float64); ok {
+		rules = append(rules, "gt="+formatNumber(v))
+	}
+	if v, ok := schema.ExclusiveMaximum.(float64); ok {
+		rules = append(rules, "lt="+formatNumber(v))
+	}
+	return rules
+}
+
+// oneOfRule builds a oneof rule from enum values. The validator separates the values with spaces, so values which
+// contain spaces or characters which are special in tags cannot be expressed.
+func oneOfRule(enum []interface{}) (string, bool) {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		switch v := e.(type) {
+		case string:
+			if v == "" || strings.ContainsAny(v, " ,|\"`'") {
+				return "", false
+			}
+			values[i] = v
+		case float64:
+			values[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return "", false
+		}
+	}
+	return "oneof=" + strings.Join(values, " "), true
+}
@@ -0,0 +1,200 @@
+package inputs
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SkipSubschemas is returned by a WalkFunc to skip the subschemas of the schema it was called for.
+var SkipSubschemas = errors.New("skip subschemas")
+
+// WalkFunc is called by Walk for each schema with its JSON pointer relative to the schema walked, e.g. "" for that
+// schema and "/properties/name" for one of its properties. Returning SkipSubschemas skips the subschemas of schema,
+// any other error stops the walk and is returned by Walk.
+type WalkFunc func(pointer string, schema *Schema) error
+
+// Subschema is a schema nested in another.
+type Subschema struct {
+	// Keyword holding the subschema, e.g. "properties" or "items".
+	Keyword string
+	// Key of the subschema in the keyword, i.e. the name of a definition or property or the index in an array of
+	// schemas such as "anyOf". It is empty for keywords holding a single schema, e.g. "items".
+	Key string
+	// Schema is the subschema.
+	Schema *Schema
+
+	// pointer of the subschema when it is not the keyword followed by the key, i.e. for the bodies of OpenAPI
+	// operations, the payloads of AsyncAPI messages and the versions of a CustomResourceDefinition, which have the
+	// name of their type or message as key.
+	pointer Pointer
+}
+
+// PathElement returns the JSON pointer of the subschema in the schema holding it without the leading slash, e.g.
+// "properties/name", "properties/a~1b" for the property "a/b", or "items".
+func (s Subschema) PathElement() string {
+	if s.pointer != nil {
+		return strings.TrimPrefix(s.pointer.String(), "/")
+	}
+	if s.Key == "" && !s.isKeyed() {
+		return s.Keyword
+	}
+	return s.Keyword + "/" + EscapePointerToken(s.Key)
+}
+
+// tokens returns the reference tokens of the JSON pointer of the subschema in the schema holding it.
+func (s Subschema) tokens() Pointer {
+	if s.pointer != nil {
+		return s.pointer
+	}
+	tokens := Pointer(strings.Split(s.Keyword, "/"))
+	if s.Key != "" || s.isKeyed() {
+		tokens = append(tokens, s.Key)
+	}
+	return tokens
+}
+
+// isKeyed reports whether the keyword holds subschemas by name, like properties, rather than a single schema.
+func (s Subschema) isKeyed() bool {
+	switch s.Keyword {
+	case "definitions", "$defs", "properties", "patternProperties", "dependencies", "dependentSchemas",
+		"components/schemas", "optionalProperties", "mapping":
+		return true
+	}
+	return s.pointer != nil
+}
+
+// isResourceRoot reports whether the subschema is the root of a schema resource, which may declare its own $schema:
+// a schema with an $id, or a schema embedded in an OpenAPI, AsyncAPI or CustomResourceDefinition document.
+func (s Subschema) isResourceRoot() bool {
+	switch s.Keyword {
+	case "components/schemas", "paths", "messages", "spec/versions":
+		return true
+	}
+	return s.Schema.ID() != ""
+}
+
+// Subschemas returns the schemas nested directly in schema: definitions, $defs, properties, patternProperties,
+// dependencies, dependentSchemas and the component schemas of an OpenAPI or AsyncAPI document in key order, the
+// bodies of OpenAPI operations, the payloads of AsyncAPI messages, the versions of a CustomResourceDefinition,
+// followed by additionalProperties, propertyNames, items, additionalItems, contains, allOf, anyOf, oneOf, not, if,
+// then and else.
+func (schema *Schema) Subschemas() []Subschema {
+	if schema.JTD {
+		return schema.jtdSubschemas()
+	}
+	var subschemas []Subschema
+	for _, keyed := range []struct {
+		keyword string
+		schemas map[string]*Schema
+	}{
+		{"definitions", schema.Definitions}, {"$defs", schema.Defs}, {"properties", schema.Properties},
+		{"patternProperties", schema.PatternProperties}, {"dependencies", schema.Dependencies},
+		{"dependentSchemas", schema.DependentSchemas},
+	} {
+		for _, k := range sortedKeys(keyed.schemas) {
+			subschemas = append(subschemas, Subschema{Keyword: keyed.keyword, Key: k, Schema: keyed.schemas[k]})
+		}
+	}
+	for _, k := range sortedKeys(schema.Components) {
+		subschemas = append(subschemas, Subschema{Keyword: "components/schemas", Key: k, Schema: schema.Components[k]})
+	}
+	for _, body := range schema.Bodies {
+		subschemas = append(subschemas, Subschema{Keyword: "paths", Key: body.Name, Schema: body.Schema, pointer: body.Pointer})
+	}
+	for _, m := range schema.Messages {
+		subschemas = append(subschemas, Subschema{Keyword: "messages", Key: m.Name, Schema: m.Schema, pointer: m.Pointer})
+	}
+	for _, cr := range schema.CustomResources {
+		subschemas = append(subschemas, Subschema{Keyword: "spec/versions", Key: cr.Name, Schema: cr.Schema, pointer: cr.Pointer})
+	}
+	if schema.AdditionalProperties != nil {
+		subschemas = append(subschemas, Subschema{Keyword: "additionalProperties", Schema: (*Schema)(schema.AdditionalProperties)})
+	}
+	for _, single := range []struct {
+		keyword string
+		schema  *Schema
+	}{{"propertyNames", schema.PropertyNames}, {"items", schema.Items}, {"additionalItems", schema.AdditionalItems}, {"contains", schema.Contains}} {
+		if single.schema != nil {
+			subschemas = append(subschemas, Subschema{Keyword: single.keyword, Schema: single.schema})
+		}
+	}
+	for _, list := range []struct {
+		keyword string
+		schemas []*Schema
+	}{{"allOf", schema.AllOf}, {"anyOf", schema.AnyOf}, {"oneOf", schema.OneOf}} {
+		for i, s := range list.schemas {
+			if s != nil {
+				subschemas = append(subschemas, Subschema{Keyword: list.keyword, Key: strconv.Itoa(i), Schema: s})
+			}
+		}
+	}
+	for _, single := range []struct {
+		keyword string
+		schema  *Schema
+	}{{"not", schema.Not}, {"if", schema.If}, {"then", schema.Then}, {"else", schema.Else}} {
+		if single.schema != nil {
+			subschemas = append(subschemas, Subschema{Keyword: single.keyword, Schema: single.schema})
+		}
+	}
+	return subschemas
+}
+
+// jtdSubschemas returns the subschemas of a schema converted from a JSON Type Definition under its keywords:
+// definitions, properties and optionalProperties in key order, followed by elements, values and mapping.
+func (schema *Schema) jtdSubschemas() []Subschema {
+	var subschemas []Subschema
+	for _, k := range sortedKeys(schema.Definitions) {
+		subschemas = append(subschemas, Subschema{Keyword: "definitions", Key: k, Schema: schema.Definitions[k]})
+	}
+	for _, k := range sortedKeys(schema.Properties) {
+		keyword := "optionalProperties"
+		if Contains(schema.Required, k) {
+			keyword = "properties"
+		}
+		subschemas = append(subschemas, Subschema{Keyword: keyword, Key: k, Schema: schema.Properties[k]})
+	}
+	if schema.Items != nil {
+		subschemas = append(subschemas, Subschema{Keyword: "elements", Schema: schema.Items})
+	}
+	if ap := schema.AdditionalProperties; ap != nil && ap.AdditionalPropertiesBool == nil {
+		subschemas = append(subschemas, Subschema{Keyword: "values", Schema: (*Schema)(ap)})
+	}
+	for _, k := range sortedKeys(schema.Mapping) {
+		subschemas = append(subschemas, Subschema{Keyword: "mapping", Key: k, Schema: schema.Mapping[k]})
+	}
+	return subschemas
+}
+
+// Walk calls fn for schema and every schema nested in it, each schema before its subschemas, which are visited in
+// the order of Subschemas.
+func (schema *Schema) Walk(fn WalkFunc) error {
+	err := schema.walk("", fn)
+	if err == SkipSubschemas {
+		return nil
+	}
+	return err
+}
+
+func (schema *Schema) walk(pointer string, fn WalkFunc) error {
+	if err := fn(pointer, schema); err != nil {
+		return err
+	}
+	for _, sub := range schema.Subschemas() {
+		err := sub.Schema.walk(pointer+"/"+sub.PathElement(), fn)
+		if err != nil && err != SkipSubschemas {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,184 @@
+package inputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsYAML reports whether the file or URI path name is a YAML document, i.e. has a .yaml or .yml extension.
+func IsYAML(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// yamlDocument is a YAML document converted to JSON, with the positions of its nodes.
+type yamlDocument struct {
+	json []byte
+	// spans of the converted nodes in document order, so parents precede their children
+	spans []yamlSpan
+}
+
+// yamlSpan is the JSON encoding of a YAML node, json[start:end], and the position of the node in the YAML document.
+type yamlSpan struct {
+	start, end   int
+	line, column int
+}
+
+// convertYAML converts a YAML document to JSON, keeping the order of mapping keys. A file holding several documents
+// is rejected, since each schema is identified by the URI of its file.
+func convertYAML(b []byte) (*yamlDocument, error) {
+	var root yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	if err := dec.Decode(&root); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, errors.New("the document is empty")
+	}
+	for {
+		var next yaml.Node
+		if err := dec.Decode(&next); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		// a trailing document separator starts an empty document
+		if len(next.Content) > 0 && !(next.Content[0].ShortTag() == "!!null" && next.Content[0].Value == "") {
+			return nil, yamlErrorf(&next, "the file holds more than one YAML document, put each document in a file of its own")
+		}
+	}
+
+	c := &yamlConverter{}
+	if err := c.convert(root.Content[0], 0); err != nil {
+		return nil, err
+	}
+	return &yamlDocument{json: c.buf.Bytes(), spans: c.spans}, nil
+}
+
+// position returns the line and column of the innermost YAML node converted to the JSON before offset, which is
+// where encoding/json reports type errors: just after the value.
+func (d *yamlDocument) position(offset int) (line int, column int, err error) {
+	offset--
+	for _, s := range d.spans {
+		if s.start <= offset && offset < s.end {
+			line, column = s.line, s.column
+		}
+	}
+	if line == 0 {
+		return 0, 0, fmt.Errorf("couldn't find offset %d in %d bytes", offset, len(d.json))
+	}
+	return line, column, nil
+}
+
+// maxAliasDepth limits how deeply aliases are expanded, guarding against documents which expand exponentially.
+const maxAliasDepth = 32
+
+type yamlConverter struct {
+	buf   bytes.Buffer
+	spans []yamlSpan
+}
+
+func (c *yamlConverter) convert(n *yaml.Node, aliases int) error {
+	span := len(c.spans)
+	c.spans = append(c.spans, yamlSpan{start: c.buf.Len(), line: n.Line, column: n.Column})
+	defer func() { c.spans[span].end = c.buf.Len() }()
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		if aliases >= maxAliasDepth {
+			return yamlErrorf(n, "aliases are nested too deeply")
+		}
+		return c.convert(n.Alias, aliases+1)
+	case yaml.MappingNode:
+		c.buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.ShortTag() == "!!merge" {
+				return yamlErrorf(key, "merge keys are not supported")
+			}
+			if key.Kind != yaml.ScalarNode {
+				return yamlErrorf(key, "mapping keys must be scalars")
+			}
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			c.writeString(key.Value)
+			c.buf.WriteByte(':')
+			if err := c.convert(value, aliases); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte('}')
+	case yaml.SequenceNode:
+		c.buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				c.buf.WriteByte(',')
+			}
+			if err := c.convert(item, aliases); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte(']')
+	case yaml.ScalarNode:
+		return c.convertScalar(n)
+	default:
+		return yamlErrorf(n, "unexpected YAML node")
+	}
+	return nil
+}
+
+// convertScalar writes null, booleans and numbers as such, and any other scalar, e.g. a timestamp, as a string.
+func (c *yamlConverter) convertScalar(n *yaml.Node) error {
+	switch n.ShortTag() {
+	case "!!null":
+		c.buf.WriteString("null")
+		return nil
+	case "!!bool", "!!int", "!!float":
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return yamlErrorf(n, "%v", err)
+		}
+		if f, ok := v.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+			return yamlErrorf(n, "%s cannot be represented in JSON", n.Value)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return yamlErrorf(n, "%v", err)
+		}
+		c.buf.Write(b)
+		return nil
+	}
+	c.writeString(n.Value)
+	return nil
+}
+
+func (c *yamlConverter) writeString(s string) {
+	b, _ := json.Marshal(s)
+	c.buf.Write(b)
+}
+
+// yamlError is an error at a node of a YAML document.
+type yamlError struct {
+	line, column int
+	msg          string
+}
+
+func (e *yamlError) Error() string {
+	return fmt.Sprintf("line %d, character %d: %s", e.line, e.column, e.msg)
+}
+
+func yamlErrorf(n *yaml.Node, format string, args ...interface{}) error {
+	return &yamlError{line: n.Line, column: n.Column, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,189 @@
+// Package schemaruntime holds the helpers called by code generated with the --runtime option. Its API is kept
+// stable so fixes reach generated code by upgrading the module rather than regenerating it.
+package schemaruntime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ErrFieldRequired is wrapped by the errors reporting required properties which were not present.
+var ErrFieldRequired = errors.New("field required validation failed")
+
+// RequiredError returns the error reporting that the required field was not present.
+func RequiredError(field string) error {
+	return fmt.Errorf("\"%s\" is required but was not present: %w", field, ErrFieldRequired)
+}
+
+// Errors returns the errors which are not nil, or nil when there are none.
+func Errors(errs ...error) []error {
+	var all []error
+	for _, err := range errs {
+		if err != nil {
+			all = append(all, err)
+		}
+	}
+	return all
+}
+
+// ObjectWriter marshals the properties of a JSON object. The first error stops all further writes and is returned
+// by Bytes.
+type ObjectWriter struct {
+	buf   bytes.Buffer
+	comma bool
+	err   error
+}
+
+// NewObjectWriter returns a writer for an empty object.
+func NewObjectWriter() *ObjectWriter {
+	w := &ObjectWriter{}
+	w.buf.WriteByte('{')
+	return w
+}
+
+// Property writes the property name with the JSON encoding of v.
+func (w *ObjectWriter) Property(name string, v interface{}) {
+	if w.err != nil {
+		return
+	}
+	key, err := json.Marshal(name)
+	if err != nil {
+		w.err = err
+		return
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		w.err = fmt.Errorf("error marshalling \"%s\": %w", name, err)
+		return
+	}
+	w.separate()
+	w.buf.Write(key)
+	w.buf.WriteByte(':')
+	w.buf.Write(value)
+}
+
+// Embedded writes the properties of the JSON object v encodes to, e.g. the fields of an embedded struct.
+func (w *ObjectWriter) Embedded(v interface{}) {
+	if w.err != nil {
+		return
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		w.err = err
+		return
+	}
+	if len(value) > 2 && value[0] == '{' {
+		w.separate()
+		w.buf.Write(value[1 : len(value)-1])
+	}
+}
+
+// Map writes the entries of m, which must be a map with string keys, as properties in key order.
+func (w *ObjectWriter) Map(m interface{}) {
+	if w.err != nil {
+		return
+	}
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		w.err = fmt.Errorf("cannot write %T as properties, expected a map with string keys", m)
+		return
+	}
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, k := range keys {
+		w.Property(k.String(), rv.MapIndex(k).Interface())
+	}
+}
+
+// Bytes closes the object and returns its encoding.
+func (w *ObjectWriter) Bytes() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	w.buf.WriteByte('}')
+	return w.buf.Bytes(), nil
+}
+
+func (w *ObjectWriter) separate() {
+	if w.comma {
+		w.buf.WriteByte(',')
+	}
+	w.comma = true
+}
+
+// Object holds the properties of a JSON object while it is unmarshalled, tracking which have been read.
+type Object struct {
+	data  []byte
+	props map[string]json.RawMessage
+	read  map[string]bool
+}
+
+// ParseObject parses the JSON object b.
+func ParseObject(b []byte) (*Object, error) {
+	o := &Object{data: b, read: map[string]bool{}}
+	if err := json.Unmarshal(b, &o.props); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Property unmarshals the property name into v when it is present, reporting whether it was.
+func (o *Object) Property(name string, v interface{}) (bool, error) {
+	raw, ok := o.props[name]
+	if !ok {
+		return false, nil
+	}
+	o.read[name] = true
+	if err := json.Unmarshal(raw, v); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// Required returns the error reporting that field is missing when the property name is not present.
+func (o *Object) Required(name, field string) error {
+	if _, ok := o.props[name]; ok {
+		return nil
+	}
+	return RequiredError(field)
+}
+
+// Embedded unmarshals the whole object into v, e.g. an embedded struct, and marks the properties v reads.
+func (o *Object) Embedded(v interface{}, names ...string) error {
+	if err := json.Unmarshal(o.data, v); err != nil {
+		return err
+	}
+	o.MarkRead(names...)
+	return nil
+}
+
+// MarkRead marks properties as read without unmarshalling them.
+func (o *Object) MarkRead(names ...string) {
+	for _, name := range names {
+		o.read[name] = true
+	}
+}
+
+// Unread returns the names of the properties which have not been read, in order.
+func (o *Object) Unread() []string {
+	var names []string
+	for name := range o.props {
+		if !o.read[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NoAdditional returns an error when any property has not been read.
+func (o *Object) NoAdditional() error {
+	if unread := o.Unread(); len(unread) > 0 {
+		return fmt.Errorf("additional property not allowed: \"%s\"", unread[0])
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package utils
 import (
 	"flag"
 	"fmt"
+	"io/fs"
 	"math/rand"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 )
@@ -49,22 +51,129 @@ func ReadFiles(inputPath string) ([]string, error) {
 	return filePaths, nil
 }
 
+// ReadFilesFS lists the file at inputPath of fsys, or the files in the directory at inputPath, as paths in fsys.
+func ReadFilesFS(fsys fs.FS, inputPath string) ([]string, error) {
+	inputPath = path.Clean(inputPath)
+	stat, err := fs.Stat(fsys, inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !stat.IsDir() {
+		//single file entry
+		return []string{inputPath}, nil
+	}
+
+	//read a directory
+	files, err := fs.ReadDir(fsys, inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var filePaths []string
+	for _, file := range files {
+		if !file.IsDir() {
+			filePaths = append(filePaths, path.Join(inputPath, file.Name()))
+		}
+	}
+
+	return filePaths, nil
+}
+
 type Flags struct {
-	InputDir    string
-	PackageName string
-	OutputPath  string
+	InputDir           string
+	PackageName        string
+	OutputPath         string
+	AlphabeticalFields bool
+	Tags               string
+	ValidateTags       bool
+	Layout             string
+	TemplateDir        string
+	Runtime            bool
+	TypeCheck          bool
+	GeneratorFlags
+}
+
+// GeneratorFlags are the flags shared by generation and the lint subcommand, which configure how schemas and the
+// documents they reference are read and how types are created.
+type GeneratorFlags struct {
+	TypeMap        string
+	Packages       string
+	RefDirs        string
+	RefMap         string
+	RefCache       string
+	RefFetch       bool
+	OpenAPIBodies  bool
+	SkipMetaSchema bool
+}
+
+// addGeneratorFlags defines the generator flags in set, which are stored in f when set is parsed.
+func addGeneratorFlags(set *flag.FlagSet, f *GeneratorFlags) {
+	set.StringVar(&f.TypeMap, "type-map", "", "Comma separated bindings of schema URIs to existing go types, e.g. common.json#/definitions/money=github.com/acme/money.Amount, naming the package after a semicolon when it isn't the last element of the import path, e.g. github.com/acme/go-money;money.Amount")
+	set.StringVar(&f.Packages, "packages", "", "Comma separated mappings of schema $id prefixes or source directories to go packages, e.g. https://schemas.example.com/common/=github.com/acme/models/common:./models/common")
+	set.StringVar(&f.RefDirs, "ref-dir", "", "Comma separated directories searched for schemas referenced by a $ref which are not inputs and not found relative to the referring schema")
+	set.StringVar(&f.RefMap, "ref-map", "", "Comma separated mappings of referenced URI prefixes to directories the documents are read from, e.g. https://schemas.example.com/=./vendor-schemas/")
+	set.StringVar(&f.RefCache, "ref-cache", "", "Directory of cached referenced documents, stored as <host>/<path>")
+	set.BoolVar(&f.RefFetch, "ref-fetch", false, "Fetch referenced http and https documents which are not found otherwise, adding them to the -ref-cache directory if set")
+	set.BoolVar(&f.OpenAPIBodies, "openapi-bodies", false, "Also generate types for the request and response bodies of the operations of OpenAPI documents, not only their component schemas")
+	set.BoolVar(&f.SkipMetaSchema, "skip-meta-schema", false, "Generate types from JSON schemas which are not valid against the meta-schema named by their $schema instead of failing")
 }
 
 func ParseFlags() Flags {
 	inputDir := flag.String("input", "../schemas", "Please enter the input directory")
 	packageName := flag.String("package", "model", "Please enter the package name of generated go file")
 	outputPath := flag.String("output", "../output.go", "Please enter the target output go file")
+	alphabeticalFields := flag.Bool("alphabetical", false, "Order struct fields alphabetically instead of in schema order")
+	tags := flag.String("tags", "", "Comma separated struct tags to add alongside json, with an optional naming convention (camel, snake, kebab or as-is), e.g. yaml:snake,bson:camel,db")
+	validateTags := flag.Bool("validate-tags", false, "Emit go-playground/validator tags derived from the schema constraints")
+	layout := flag.String("layout", "single", "Output layout: single (one file), schema (one file per input schema) or type (one file per generated type)")
+	templateDir := flag.String("templates", "", "Directory of <name>.tmpl files overriding the struct, alias, marshal, unmarshal, validate, helpers, registry or union templates")
+	runtime := flag.Bool("runtime", false, "Generate methods calling the schemaruntime package of this module instead of inlining their code")
+	typeCheck := flag.Bool("typecheck", false, "Type-check the generated code before writing it, reporting compile errors against the schemas that produced them")
+	var generator GeneratorFlags
+	addGeneratorFlags(flag.CommandLine, &generator)
 	flag.Parse()
 
 	return Flags{
-		InputDir:    *inputDir,
-		PackageName: *packageName,
-		OutputPath:  *outputPath,
+		InputDir:           *inputDir,
+		PackageName:        *packageName,
+		OutputPath:         *outputPath,
+		AlphabeticalFields: *alphabeticalFields,
+		Tags:               *tags,
+		ValidateTags:       *validateTags,
+		Layout:             *layout,
+		TemplateDir:        *templateDir,
+		Runtime:            *runtime,
+		TypeCheck:          *typeCheck,
+		GeneratorFlags:     generator,
+	}
+}
+
+type LintFlags struct {
+	InputDir string
+	Format   string
+	Output   string
+	Strict   bool
+	GeneratorFlags
+}
+
+// ParseLintFlags parses the flags of the lint subcommand from args, the arguments following "lint".
+func ParseLintFlags(args []string) LintFlags {
+	set := flag.NewFlagSet("lint", flag.ExitOnError)
+	inputDir := set.String("input", "../schemas", "Please enter the input file or directory")
+	format := set.String("format", "text", "Output format: text (one finding per line) or sarif (SARIF 2.1.0)")
+	output := set.String("output", "", "File the findings are written to instead of stdout")
+	strict := set.Bool("strict", false, "Exit with a failure status on warnings as well as errors")
+	var generator GeneratorFlags
+	addGeneratorFlags(set, &generator)
+	_ = set.Parse(args)
+
+	return LintFlags{
+		InputDir:       *inputDir,
+		Format:         *format,
+		Output:         *output,
+		Strict:         *strict,
+		GeneratorFlags: generator,
 	}
 }
 
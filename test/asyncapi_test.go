@@ -0,0 +1,98 @@
+package test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	model "github.com/brenank/json-schema-to-go-struct-generator/test/generated/asyncapi"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go run ../cmd/main.go --input ./samples/asyncapi --output ./generated/asyncapi/model.go --package asyncapi
+
+func TestThatMessagesAreDecodedByName(t *testing.T) {
+	v, err := model.DecodeMessage("userSignedUp", []byte(`{"user":{"kind":"admin","email":"alice@example.com"},"at":"2021-03-04T05:06:07Z"}`))
+	assert.Nil(t, err)
+	if signedUp, ok := v.(*model.UserSignedUp); assert.True(t, ok, "unexpected type %T", v) {
+		assert.Equal(t, "alice@example.com", signedUp.User.Email)
+	}
+
+	// channel messages are named after their name or messageId, and payloads referring to a schema use its type
+	v, err = model.DecodeMessage("userDeleted", []byte(`{"userId":"u-1"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, &model.UserDeleted{UserId: "u-1"}, v)
+	v, err = model.DecodeMessage("userPurged", []byte(`{"email":"bob@example.com"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, &model.User{Email: "bob@example.com"}, v)
+
+	_, err = model.DecodeMessage("userRenamed", []byte(`{}`))
+	assert.EqualError(t, err, `unknown message "userRenamed"`)
+}
+
+func TestThatTheMessageRegistryHasItsOwnFile(t *testing.T) {
+	fsys := fstest.MapFS{"events.yaml": {Data: []byte(`asyncapi: 2.6.0
+info: { title: Events, version: 1.0.0 }
+channels:
+  orders:
+    subscribe:
+      message:
+        name: orderPlaced
+        payload:
+          type: object
+          properties:
+            id: { type: string }
+`)}}
+
+	opts := converter.Options{PackageName: "events"}
+	opts.Output.Layout = "type"
+	pkgs, err := converter.GenerateFS(fsys, []string{"events.yaml"}, opts)
+	if !assert.Nil(t, err) || !assert.Len(t, pkgs, 1) {
+		return
+	}
+	var names []string
+	for _, f := range pkgs[0].Files {
+		names = append(names, f.Name)
+		if f.Name == "schema_messages.go" {
+			assert.Contains(t, string(f.Content), `"orderPlaced": func() interface{} { return new(OrderPlaced) },`)
+		}
+	}
+	assert.Equal(t, []string{"order_placed.go", "schema_messages.go"}, names)
+}
+
+func TestThatPayloadsMayDeclareTheirDialect(t *testing.T) {
+	document := `{ "asyncapi": "2.6.0", "components": { "messages": { "Ping": {
+        "schemaFormat": "application/schema+json;version=draft-07",
+        "payload": { "$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": { "at": { "type": "string" } } } } } } }`
+	code, err := generateFromFS(fstest.MapFS{"events.json": {Data: []byte(document)}}, converter.Options{}, "events.json")
+	assert.Nil(t, err)
+	assert.Contains(t, code, "type Ping struct { At string `json:\"at,omitempty\"` }")
+}
+
+func TestThatUnsupportedMessagesAreRejected(t *testing.T) {
+	for name, test := range map[string]struct {
+		document string
+		expected string
+	}{
+		"format": {
+			document: `{ "asyncapi": "2.6.0", "components": { "messages": { "Ping": {
+                "schemaFormat": "application/vnd.apache.avro;version=1.9.0", "payload": { "type": "record" } } } } }`,
+			expected: `the payload of the message at "/components/messages/Ping" has the unsupported schemaFormat "application/vnd.apache.avro;version=1.9.0"`,
+		},
+		"duplicate": {
+			document: `{ "asyncapi": "2.6.0", "components": { "messages": {
+                "Ping": { "name": "ping", "payload": { "type": "object" } },
+                "Pong": { "name": "ping", "payload": { "type": "object" } } } } }`,
+			expected: `the message name "ping" at "/components/messages/Pong" is already used at "/components/messages/Ping"`,
+		},
+		"version": {
+			document: `{ "asyncapi": "3.0.0" }`,
+			expected: `unsupported AsyncAPI version "3.0.0"`,
+		},
+	} {
+		_, err := generateFromFS(fstest.MapFS{"events.json": {Data: []byte(test.document)}}, converter.Options{}, "events.json")
+		if assert.NotNil(t, err, name) {
+			assert.Contains(t, err.Error(), test.expected, name)
+		}
+	}
+}
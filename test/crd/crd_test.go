@@ -0,0 +1,54 @@
+// Package crd tests the types generated from CustomResourceDefinitions against k8s.io/apimachinery. It is a module of
+// its own, so the generator doesn't require the Kubernetes modules.
+package crd
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	crd "github.com/brenank/json-schema-to-go-struct-generator/test/crd/generated"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+//go:generate go run github.com/brenank/json-schema-to-go-struct-generator/cmd --input ../samples/crd --output ./generated/model.go --package crd --typecheck
+
+func TestThatCustomResourceDefinitionsTypeCheck(t *testing.T) {
+	_, err := converter.GenerateFS(os.DirFS("../samples/crd"), []string{"crontab.yaml"}, converter.Options{PackageName: "crd", TypeCheck: true})
+	assert.Nil(t, err)
+}
+
+func TestThatCustomResourcesRoundTrip(t *testing.T) {
+	resource := `{
+  "apiVersion": "stable.example.com/v1",
+  "kind": "CronTab",
+  "metadata": { "name": "backup", "namespace": "jobs", "creationTimestamp": null },
+  "spec": {
+    "cronSpec": "0 * * * *",
+    "replicas": "50%",
+    "template": { "apiVersion": "v1", "kind": "Pod", "spec": { "restartPolicy": "Never" } },
+    "target": { "apiVersion": "apps/v1", "kind": "Deployment", "metadata": { "name": "web", "creationTimestamp": null }, "size": 3, "paused": true }
+  },
+  "owner": "ops"
+}`
+
+	var cronTab crd.CronTab
+	if !assert.Nil(t, json.Unmarshal([]byte(resource), &cronTab)) {
+		return
+	}
+	assert.Nil(t, cronTab.Validate())
+	assert.Equal(t, "CronTab", cronTab.Kind)
+	assert.Equal(t, "backup", cronTab.Metadata.Name)
+	assert.Equal(t, intstr.FromString("50%"), cronTab.Spec.Replicas)
+	assert.Equal(t, "Deployment", cronTab.Spec.Target.Kind)
+	// apiVersion and kind are read by the type meta, so only the unknown fields are preserved
+	assert.Equal(t, map[string]interface{}{"owner": "ops"}, cronTab.AdditionalProperties)
+	assert.Equal(t, map[string]interface{}{"paused": true}, cronTab.Spec.Target.AdditionalProperties)
+
+	// the type meta is written once and the object meta writes its empty creation timestamp
+	b, err := json.Marshal(&cronTab)
+	assert.Nil(t, err)
+	assert.JSONEq(t, resource, string(b))
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/stretchr/testify/assert"
+)
+
+const crontabCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  scope: Namespaced
+  names:
+    kind: CronTab
+    plural: crontabs
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          required: [spec]
+          properties:
+            apiVersion: { type: string }
+            kind: { type: string }
+            metadata: { type: object }
+            spec:
+              type: object
+              required: [cronSpec]
+              properties:
+                cronSpec: { type: string }
+                replicas: { x-kubernetes-int-or-string: true }
+                template:
+                  type: object
+                  x-kubernetes-embedded-resource: true
+                  x-kubernetes-preserve-unknown-fields: true
+                config:
+                  type: object
+                  x-kubernetes-preserve-unknown-fields: true
+                  properties:
+                    level: { type: string }
+                labels:
+                  type: object
+                  x-kubernetes-preserve-unknown-fields: true
+                target:
+                  type: object
+                  x-kubernetes-embedded-resource: true
+                  properties:
+                    apiVersion: { type: string }
+                    kind: { type: string }
+                    metadata: { type: object }
+                    size: { type: integer }
+            status:
+              type: object
+              properties:
+                ready: { type: boolean }
+`
+
+func TestThatCustomResourceDefinitionsAreGenerated(t *testing.T) {
+	code, err := generateFromFS(fstest.MapFS{"crds/crontab.yaml": {Data: []byte(crontabCRD)}}, converter.Options{}, "crds/crontab.yaml")
+	assert.Nil(t, err)
+	for _, expected := range []string{
+		`"k8s.io/apimachinery/pkg/apis/meta/v1"`,
+		"type CronTab struct { v1.TypeMeta `json:\",inline\"` Metadata v1.ObjectMeta `json:\"metadata,omitempty\"` Spec *Spec `json:\"spec\"`",
+		"Replicas intstr.IntOrString `json:\"replicas,omitempty\"`",
+		"Template runtime.RawExtension `json:\"template,omitempty\"`",
+		"Labels map[string]interface{} `json:\"labels,omitempty\"`",
+		"type Config struct { Level string `json:\"level,omitempty\"` AdditionalProperties map[string]interface{} `json:\"-\"` }",
+		"type Target struct { v1.TypeMeta `json:\",inline\"` Metadata v1.ObjectMeta `json:\"metadata,omitempty\"` Size int `json:\"size,omitempty\"` }",
+	} {
+		assert.Contains(t, code, expected)
+	}
+	// the definition itself has no type, and apiVersion and kind are read by the type meta
+	assert.NotContains(t, code, "Crontabs")
+	assert.NotContains(t, code, "ApiVersion")
+}
@@ -7,12 +7,14 @@ import (
 	"testing"
 
 	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
 	"github.com/stretchr/testify/assert"
 
 	example1 "github.com/brenank/json-schema-to-go-struct-generator/test/generated/example1"
 )
 
-//go:generate go run ../cmd/main.go --input ./samples/example1 --output ./generated/example1/model.go
+// example1a.json uses the draft-04 boolean exclusiveMinimum while naming draft-07 as its $schema
+//go:generate go run ../cmd/main.go --input ./samples/example1 --output ./generated/example1/model.go --skip-meta-schema
 
 func TestMarshalValidateSuccess(t *testing.T) {
 	param := struct {
@@ -61,6 +63,26 @@ func TestGenerateExample(t *testing.T) {
 	files := []string{
 		path.Join(os.Getenv("PWD"), "./samples/example1/example1a.json"),
 	}
-	err := converter.Convert(files, "models", "./generated/example1/generate-example/all.go", true)
+	err := converter.ConvertWithOptions(files, "./generated/example1/generate-example/all.go", converter.Options{
+		PackageName: "models",
+		Generator:   inputs.GeneratorOptions{SkipMetaSchema: true},
+		Output:      inputs.OutputOptions{Debug: true},
+	})
+	assert.Nil(t, err)
+}
+
+func TestThatSchemasNotValidAgainstTheirMetaSchemaAreRejected(t *testing.T) {
+	fsys := os.DirFS("./samples/metaschema")
+	_, err := converter.GenerateFS(fsys, []string{"product.json"}, converter.Options{PackageName: "models"})
+	var metaErr *inputs.MetaSchemaError
+	if assert.ErrorAs(t, err, &metaErr) && assert.Len(t, metaErr.Violations, 1) {
+		assert.Equal(t, inputs.Pointer{"properties", "price", "exclusiveMinimum"}, metaErr.Violations[0].Pointer)
+		assert.Equal(t, 12, metaErr.Violations[0].Line)
+	}
+
+	_, err = converter.GenerateFS(fsys, []string{"product.json"}, converter.Options{
+		PackageName: "models",
+		Generator:   inputs.GeneratorOptions{SkipMetaSchema: true},
+	})
 	assert.Nil(t, err)
 }
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	model "github.com/brenank/json-schema-to-go-struct-generator/test/generated/extensions"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go run ../cmd/main.go --input ./samples/extensions --output ./generated/extensions/model.go
+
+func TestThatGoExtensionsAreApplied(t *testing.T) {
+	j := `{"id":"o-1","createdBy":"alice","createdAt":"2021-03-04T05:06:07Z","count":3,"customer":{"name":"bob"}}`
+
+	o := &model.Order{}
+	err := json.Unmarshal([]byte(j), o)
+	assert.Nil(t, err)
+	assert.Nil(t, o.Validate())
+
+	// x-go-name
+	assert.Equal(t, "o-1", o.ID)
+	assert.Equal(t, "bob", o.Buyer.Name)
+
+	// x-go-embed and x-go-type
+	assert.Equal(t, "alice", o.CreatedBy)
+	assert.Equal(t, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), o.CreatedAt)
+
+	// x-go-pointer
+	if assert.NotNil(t, o.Count) {
+		assert.Equal(t, 3, *o.Count)
+	}
+
+	op, err := json.Marshal(o)
+	assert.Nil(t, err)
+
+	// x-go-omitempty forces the empty note to be written
+	var roundtripped map[string]interface{}
+	assert.Nil(t, json.Unmarshal(op, &roundtripped))
+	assert.Equal(t, "alice", roundtripped["createdBy"])
+	assert.Equal(t, "2021-03-04T05:06:07Z", roundtripped["createdAt"])
+	assert.Contains(t, roundtripped, "note")
+	assert.NotContains(t, roundtripped, "comment")
+}
+
+func TestThatOmitEmptyIsHonouredByMarshalJSON(t *testing.T) {
+	// the empty note is written since x-go-omitempty is false, the empty comment is not since it is true
+	b, err := json.Marshal(&model.Order{ID: "o-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"o-1","count":null,"note":"","customer":null}`, string(b))
+
+	b, err = json.Marshal(&model.Order{ID: "o-1", Note: "ring", Comment: "fragile"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"o-1","count":null,"note":"ring","comment":"fragile","customer":null}`, string(b))
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"embed"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:embed samples/marshal
+var embeddedSamples embed.FS
+
+func TestThatSchemasCanBeReadFromAnEmbeddedFS(t *testing.T) {
+	files, err := utils.ReadFilesFS(embeddedSamples, "samples/marshal")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"samples/marshal/test.json", "samples/marshal/validation-errors.json"}, files)
+
+	pkgs, err := converter.GenerateFS(embeddedSamples, files, converter.Options{PackageName: "marshal"})
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(pkgs)) && assert.Equal(t, 1, len(pkgs[0].Files)) {
+		code := string(pkgs[0].Files[0].Content)
+		assert.True(t, strings.Contains(code, "// Source paths:  samples/marshal/test.json:samples/marshal/validation-errors.json"), code)
+		assert.True(t, strings.Contains(code, "type Example struct"), code)
+	}
+}
+
+func TestThatRelativeReferencesResolveWithinTheFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/orders/order.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Order",
+            "type": "object",
+            "properties": {
+                "delivery": { "$ref": "../common/address.json" },
+                "street": { "$ref": "../common/address.json#/definitions/street" }
+            }
+        }`)},
+		"schemas/common/address.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Address",
+            "type": "object",
+            "properties": { "town": { "type": "string" } },
+            "definitions": {
+                "street": { "type": "object", "properties": { "name": { "type": "string" } } }
+            }
+        }`)},
+		"schemas/orders/notes": {Data: []byte("not a schema")},
+	}
+
+	files, err := utils.ReadFilesFS(fsys, "schemas/orders/order.json")
+	assert.Nil(t, err)
+	files = append(files, "schemas/common/address.json")
+
+	pkgs, err := converter.GenerateFS(fsys, files, converter.Options{PackageName: "orders"})
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(pkgs)) {
+		code := string(pkgs[0].Files[0].Content)
+		assert.True(t, strings.Contains(code, "Delivery *Address `json:\"delivery,omitempty\"`"), code)
+		assert.True(t, strings.Contains(code, "Street   *Street  `json:\"street,omitempty\"`"), code)
+	}
+}
@@ -0,0 +1,69 @@
+package generate
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestThatEachCustomResourceVersionIsParsed(t *testing.T) {
+	s := `{
+        "apiVersion": "apiextensions.k8s.io/v1",
+        "kind": "CustomResourceDefinition",
+        "metadata": { "name": "widgets.example.com" },
+        "spec": {
+            "group": "example.com",
+            "names": { "kind": "Widget", "plural": "widgets" },
+            "versions": [
+                { "name": "v1beta1", "schema": { "openAPIV3Schema": { "type": "object", "properties": { "size": { "type": "string" } } } } },
+                { "name": "v1", "schema": { "openAPIV3Schema": { "type": "object", "properties": { "size": { "type": "integer" } } } } }
+            ]
+        }
+    }`
+	so, err := js_inputs.ParseSource("widgets.json", []byte(s), &url.URL{Scheme: "file", Path: "/widgets.json"}, true)
+	if err != nil {
+		t.Fatal("It was not possible to parse the CustomResourceDefinition:", err)
+	}
+	if so.CRD != "widgets.example.com" || len(so.CustomResources) != 2 {
+		t.Fatalf("unexpected definition %q with %d versions", so.CRD, len(so.CustomResources))
+	}
+	for i, name := range []string{"WidgetV1beta1", "WidgetV1"} {
+		if cr := so.CustomResources[i]; cr.Name != name || cr.Group != "example.com" || cr.Kind != "Widget" {
+			t.Errorf("unexpected version %d: %+v", i, cr)
+		}
+	}
+
+	resolver := js_inputs.NewRefResolver([]*js_inputs.Schema{so})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+	size := so.CustomResources[1].Schema.Properties["size"]
+	if path := resolver.GetPath(size); path != "#/spec/versions/1/schema/openAPIV3Schema/properties/size" {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+	for _, name := range []string{"WidgetV1beta1", "WidgetV1"} {
+		if _, ok := g.Structs[name]; !ok {
+			t.Errorf("expected a %s struct, got %v", name, g.Structs)
+		}
+	}
+}
+
+func TestThatUnsupportedCustomResourceDefinitionsAreRejected(t *testing.T) {
+	s := `{
+        "apiVersion": "apiextensions.k8s.io/v1beta1",
+        "kind": "CustomResourceDefinition",
+        "metadata": { "name": "widgets.example.com" },
+        "spec": { "group": "example.com", "names": { "kind": "Widget" } }
+    }`
+	_, err := js_inputs.ParseSource("widgets.json", []byte(s), &url.URL{Scheme: "file", Path: "/widgets.json"}, true)
+	if err == nil || !strings.Contains(err.Error(), `unsupported CustomResourceDefinition version "apiextensions.k8s.io/v1beta1"`) {
+		t.Errorf("expected the version to be rejected, got %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package generate
 
 import (
 	"encoding/json"
+	"net/url"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -778,6 +780,88 @@ func TestTypeAliases(t *testing.T) {
 	}
 }
 
+func TestThatPropertiesBecomingTheSameFieldAreRejected(t *testing.T) {
+	tests := []struct {
+		description string
+		document    string
+		err         string
+		fields      []string
+	}{
+		{
+			description: "Keys differing only by separators become the same field.",
+			document:    `{"title": "Order", "properties": {"foo-bar": {"type": "string"}, "foo_bar": {"type": "integer"}}}`,
+			err:         `object at "file:///order.json": the properties "foo-bar" and "foo_bar" both become the field FooBar, set x-go-name to tell them apart`,
+		},
+		{
+			description: "A property named additionalProperties becomes the field holding the additional properties.",
+			document:    `{"title": "Order", "properties": {"additionalProperties": {"type": "string"}}, "additionalProperties": {"type": "integer"}}`,
+			err:         `object at "file:///order.json": the property "additionalProperties" becomes the field AdditionalProperties, which holds the additional properties, set x-go-name to tell them apart`,
+		},
+		{
+			description: "A property becomes the generated Validate method of an object with required properties.",
+			document:    `{"title": "Order", "properties": {"validate": {"type": "boolean"}, "id": {"type": "string"}}, "required": ["validate"]}`,
+			err:         `object at "file:///order.json": the property "validate" becomes the field Validate, which is the name of a generated method, set x-go-name to tell them apart`,
+		},
+		{
+			description: "A property becomes the generated MarshalJSON method of an object with additional properties.",
+			document:    `{"title": "Order", "properties": {"marshalJSON": {"type": "string"}}, "additionalProperties": false}`,
+			err:         `object at "file:///order.json": the property "marshalJSON" becomes the field MarshalJSON, which is the name of a generated method, set x-go-name to tell them apart`,
+		},
+		{
+			description: "A property becomes the generated UnmarshalJSON method.",
+			document:    `{"title": "Order", "properties": {"unmarshalJSON": {"type": "string"}, "id": {"type": "string"}}, "required": ["id"]}`,
+			err:         `object at "file:///order.json": the property "unmarshalJSON" becomes the field UnmarshalJSON, which is the name of a generated method, set x-go-name to tell them apart`,
+		},
+		{
+			description: "A property becomes the field holding the validation error of a required property.",
+			document:    `{"title": "Order", "properties": {"id": {"type": "string"}, "error": {"type": "string", "x-go-name": "_id_ValidationError"}}, "required": ["id"]}`,
+			err:         `object at "file:///order.json": the property "error" becomes the field _id_ValidationError, which holds the validation error of the required property "id", set x-go-name to tell them apart`,
+		},
+		{
+			description: "Objects without generated methods may have a property named validate.",
+			document:    `{"title": "Order", "properties": {"validate": {"type": "boolean"}}}`,
+			fields:      []string{"Validate"},
+		},
+		{
+			description: "x-go-name tells the fields apart.",
+			document:    `{"title": "Order", "properties": {"foo-bar": {"type": "string"}, "foo_bar": {"type": "integer", "x-go-name": "FooBarCount"}}}`,
+			fields:      []string{"FooBar", "FooBarCount"},
+		},
+		{
+			description: "No field holds additional properties when they are not allowed.",
+			document:    `{"title": "Order", "properties": {"additionalProperties": {"type": "string"}}, "additionalProperties": false}`,
+			fields:      []string{"AdditionalProperties"},
+		},
+	}
+
+	for _, test := range tests {
+		schema, err := js_inputs.ParseSource("order.json", []byte(test.document), &url.URL{Scheme: "file", Path: "/order.json"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := js_inputs.New(schema)
+		err = g.CreateTypes()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("For test '%s', expected the error %q but got %v.", test.description, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("For test '%s', unexpected error %v.", test.description, err)
+			continue
+		}
+		var fields []string
+		for name := range g.Structs["Order"].Fields {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		if strings.Join(fields, ",") != strings.Join(test.fields, ",") {
+			t.Errorf("For test '%s', expected the fields %v but got %v.", test.description, test.fields, fields)
+		}
+	}
+}
+
 // Root is an example of a generated type.
 type Root struct {
 	Name interface{} `json:"name,omitempty"`
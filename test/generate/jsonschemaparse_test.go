@@ -2,6 +2,8 @@ package generate
 
 import (
 	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 
 	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
@@ -26,6 +28,28 @@ func TestThatAMissingSchemaKeyResultsInAnError(t *testing.T) {
 	}
 }
 
+func TestThatSchemaKeysAreOnlyAllowedAtTheRootOfSchemaResources(t *testing.T) {
+	nested := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "root",
+        "anyOf": [{ "$schema": "http://json-schema.org/draft-07/schema#", "type": "string" }]
+    }`
+	_, err := js_inputs.Parse(nested, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"})
+	if err == nil || err.Error() != "invalid $schema keyword: /anyOf/0" {
+		t.Errorf("expected the $schema key of the anyOf subschema to be rejected, got %v", err)
+	}
+
+	// a subschema with an $id is a schema resource of its own
+	embedded := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "root",
+        "anyOf": [{ "$id": "https://example.com/name.json", "$schema": "http://json-schema.org/draft-07/schema#", "type": "string" }]
+    }`
+	if _, err := js_inputs.Parse(embedded, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"}); err != nil {
+		t.Errorf("expected the $schema key of the embedded resource to be accepted, got %v", err)
+	}
+}
+
 func TestThatTheRootSchemaCanBeParsed(t *testing.T) {
 	s := `{
         "$schema": "http://json-schema.org/schema#",
@@ -167,3 +191,51 @@ func TestReturnedSchemaId(t *testing.T) {
 		}
 	}
 }
+
+func TestThatPropertyOrderIsRecorded(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/schema#",
+        "title": "root",
+        "properties": {
+            "zulu": { "type": "string" },
+            "alpha": {
+                "type": "object",
+                "properties": {
+                    "second": { "type": "string" },
+                    "first": { "type": "string" }
+                }
+            },
+            "mike": { "type": "integer" }
+        }
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"})
+
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	expected := []string{"zulu", "alpha", "mike"}
+	if !reflect.DeepEqual(so.OrderedPropertyNames(), expected) {
+		t.Errorf("expected property order %v, but got %v", expected, so.OrderedPropertyNames())
+	}
+
+	expected = []string{"second", "first"}
+	if !reflect.DeepEqual(so.Properties["alpha"].OrderedPropertyNames(), expected) {
+		t.Errorf("expected nested property order %v, but got %v", expected, so.Properties["alpha"].OrderedPropertyNames())
+	}
+}
+
+func TestThatTypeErrorsInSubschemasReportTheirLine(t *testing.T) {
+	s := `{
+    "$schema": "http://json-schema.org/schema#",
+    "title": "root",
+    "properties": {
+        "id": { "type": "string" },
+        "name": { "type": "string", "maxLength": "long" }
+    }
+}`
+	_, err := js_inputs.ParseSource("root.json", []byte(s), &url.URL{Scheme: "file", Path: "/root.json"}, true)
+	if err == nil || !strings.Contains(err.Error(), "See input file root.json line 6") {
+		t.Errorf("expected the error to report line 6, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package generate
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestThatJTDDocumentsAreParsed(t *testing.T) {
+	so, err := js_inputs.ParseSource("user.jtd.yaml", []byte(`
+definitions:
+  name: { type: string }
+properties:
+  name: { ref: name }
+optionalProperties:
+  friends:
+    elements: { ref: name, nullable: true }
+`), &url.URL{Scheme: "file", Path: "/user.jtd.yaml"}, true)
+	if err != nil {
+		t.Fatal("It was not possible to parse the JSON Type Definition:", err)
+	}
+	if so.Title != "user" || len(so.Required) != 1 || so.Required[0] != "name" {
+		t.Errorf("unexpected schema %q requiring %v", so.Title, so.Required)
+	}
+
+	friend := so.Properties["friends"].Items
+	if friend.Reference != "#/definitions/name" || !friend.Nullable {
+		t.Errorf("expected a nullable reference to the name, got %q", friend.Reference)
+	}
+	resolver := js_inputs.NewRefResolver([]*js_inputs.Schema{so})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if path := resolver.GetPath(friend); path != "#/optionalProperties/friends/elements" {
+		t.Errorf("unexpected path %s", path)
+	}
+	resolved, err := resolver.GetSchemaByReference(friend)
+	if err != nil || resolved != so.Definitions["name"] {
+		t.Errorf("expected the ref to resolve to the definition, got %v", err)
+	}
+}
+
+func TestThatInvalidJTDDocumentsAreRejected(t *testing.T) {
+	for name, test := range map[string]struct {
+		document string
+		expected string
+	}{
+		"forms": {
+			document: `{ "properties": { "a": { "type": "string", "elements": {} } } }`,
+			expected: `the schema at "/properties/a" mixes the forms type and elements`,
+		},
+		"ref": {
+			document: `{ "values": { "ref": "missing" } }`,
+			expected: `the ref "missing" at "/values" is not a definition`,
+		},
+		"keyword": {
+			document: `{ "properties": { "a": { "type": "string", "format": "email" } } }`,
+			expected: `unknown JSON Type Definition keyword "format"`,
+		},
+		"type": {
+			document: `{ "type": "int64" }`,
+			expected: `unknown type "int64" at ""`,
+		},
+		"mapping": {
+			document: `{ "discriminator": "kind", "mapping": { "a": { "properties": { "kind": { "type": "string" } } } } }`,
+			expected: `the mapping "a" at "" must not define the discriminator "kind"`,
+		},
+	} {
+		_, err := js_inputs.ParseSource("test.jtd.json", []byte(test.document), &url.URL{Scheme: "file", Path: "/test.jtd.json"}, true)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package generate
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func layoutGenerator(t *testing.T) *js_inputs.Generator {
+	schemas := parsePackageSchemas(t, map[string]string{
+		"customer.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Customer",
+            "type": "object",
+            "properties": {
+                "name": { "type": "string" },
+                "address": { "$ref": "address.json" },
+                "loyalty": {
+                    "type": "object",
+                    "properties": { "points": { "type": "integer" } }
+                }
+            },
+            "required": ["name"]
+        }`,
+		"address.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Address",
+            "type": "object",
+            "properties": { "street": { "type": "string" } }
+        }`,
+		"bill_test.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "BillLinux",
+            "type": "object",
+            "properties": { "total": { "type": "number" } }
+        }`,
+	})
+
+	g := js_inputs.New(schemas...)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+	return g
+}
+
+func outputFiles(t *testing.T, g *js_inputs.Generator, layout js_inputs.Layout) map[string]string {
+	files, err := js_inputs.OutputFiles(g, nil, "models", nil, js_inputs.OutputOptions{Layout: layout, FileName: "model.go"})
+	if err != nil {
+		t.Fatal("Failed to output files:", err)
+	}
+
+	contents := map[string]string{}
+	for _, f := range files {
+		if _, err := parser.ParseFile(token.NewFileSet(), f.Name, f.Content, parser.AllErrors); err != nil {
+			t.Errorf("%s is not valid go: %v\n%s", f.Name, err, f.Content)
+		}
+		contents[f.Name] = string(f.Content)
+	}
+	return contents
+}
+
+func fileNames(files map[string]string) []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestThatTheSingleFileLayoutWritesOneFile(t *testing.T) {
+	files := outputFiles(t, layoutGenerator(t), js_inputs.LayoutSingleFile)
+
+	if len(files) != 1 || files["model.go"] == "" {
+		t.Fatalf("expected only model.go, got %v", fileNames(files))
+	}
+	if !strings.Contains(files["model.go"], "var ErrFieldRequired") {
+		t.Errorf("expected the helpers in model.go")
+	}
+}
+
+func TestThatThePerSchemaLayoutWritesAFilePerSchema(t *testing.T) {
+	files := outputFiles(t, layoutGenerator(t), js_inputs.LayoutPerSchema)
+
+	expected := []string{"address.go", "bill_test_gen.go", "customer.go", js_inputs.HelpersFileName}
+	if len(files) != len(expected) {
+		t.Fatalf("expected files %v, got %v", expected, fileNames(files))
+	}
+	for _, name := range expected {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expected files %v, got %v", expected, fileNames(files))
+		}
+	}
+
+	if !strings.Contains(files["customer.go"], "type Customer struct") || !strings.Contains(files["customer.go"], "type Loyalty struct") {
+		t.Errorf("expected Customer and Loyalty in customer.go, got:\n%s", files["customer.go"])
+	}
+	if !strings.Contains(files["customer.go"], "func (strct *Customer) UnmarshalJSON") {
+		t.Errorf("expected the methods of Customer in customer.go")
+	}
+	if strings.Contains(files["customer.go"], "type Address struct") {
+		t.Errorf("expected Address to be in address.go only")
+	}
+	if !strings.Contains(files["customer.go"], "// Source paths:  /schemas/customer.json") {
+		t.Errorf("expected customer.go to refer to its schema, got:\n%s", files["customer.go"])
+	}
+}
+
+func TestThatThePerTypeLayoutWritesAFilePerType(t *testing.T) {
+	files := outputFiles(t, layoutGenerator(t), js_inputs.LayoutPerType)
+
+	expected := []string{"address.go", "bill_linux_gen.go", "customer.go", "loyalty.go", js_inputs.HelpersFileName}
+	if len(files) != len(expected) {
+		t.Fatalf("expected files %v, got %v", expected, fileNames(files))
+	}
+	for _, name := range expected {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expected files %v, got %v", expected, fileNames(files))
+		}
+	}
+	if !strings.Contains(files["loyalty.go"], "type Loyalty struct") || strings.Contains(files["loyalty.go"], "type Customer struct") {
+		t.Errorf("expected only Loyalty in loyalty.go, got:\n%s", files["loyalty.go"])
+	}
+}
+
+func TestThatHelpersAreOnlyDeclaredInTheHelpersFile(t *testing.T) {
+	files := outputFiles(t, layoutGenerator(t), js_inputs.LayoutPerType)
+
+	for name, content := range files {
+		declared := strings.Contains(content, "var ErrFieldRequired")
+		if name == js_inputs.HelpersFileName && !declared {
+			t.Errorf("expected ErrFieldRequired to be declared in %s", name)
+		}
+		if name != js_inputs.HelpersFileName && declared {
+			t.Errorf("expected ErrFieldRequired not to be declared in %s", name)
+		}
+		if name != js_inputs.HelpersFileName && strings.Contains(content, "\"errors\"") {
+			t.Errorf("expected %s not to import errors, got:\n%s", name, content)
+		}
+	}
+}
+
+func TestThatLayoutsCanBeParsed(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected js_inputs.Layout
+	}{
+		{"", js_inputs.LayoutSingleFile},
+		{"single", js_inputs.LayoutSingleFile},
+		{"Schema", js_inputs.LayoutPerSchema},
+		{"type", js_inputs.LayoutPerType},
+	}
+	for _, test := range tests {
+		actual, err := js_inputs.ParseLayout(test.input)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.input, err)
+		}
+		if actual != test.expected {
+			t.Errorf("expected %q to parse as %q, got %q", test.input, test.expected, actual)
+		}
+	}
+
+	if _, err := js_inputs.ParseLayout("package"); err == nil {
+		t.Errorf("expected an error for an unknown layout")
+	}
+}
@@ -0,0 +1,142 @@
+package generate
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestParseRefMap(t *testing.T) {
+	prefixes, err := js_inputs.ParseRefMap("https://schemas.example.com/=./vendor-schemas/, https://schemas.example.com/v2/=./v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prefixes) != 2 || prefixes["https://schemas.example.com/"] != "./vendor-schemas/" ||
+		prefixes["https://schemas.example.com/v2/"] != "./v2" {
+		t.Errorf("unexpected mappings %v", prefixes)
+	}
+
+	for _, invalid := range []string{"https://schemas.example.com/", "=dir", "a=b,a=c"} {
+		if _, err := js_inputs.ParseRefMap(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestThatPrefixLoaderUsesTheLongestPrefix(t *testing.T) {
+	l := &js_inputs.PrefixLoader{
+		Prefixes: map[string]string{
+			"https://schemas.example.com/":    "vendor",
+			"https://schemas.example.com/v2/": "v2",
+		},
+		FS: fstest.MapFS{
+			"vendor/common/address.json": {Data: []byte("v1")},
+			"v2/common/address.json":     {Data: []byte("v2")},
+		},
+	}
+
+	for uri, expected := range map[string]string{
+		"https://schemas.example.com/common/address.json":    "v1",
+		"https://schemas.example.com/v2/common/address.json": "v2",
+	} {
+		b, err := l.Load(mustParseURL(t, uri))
+		if err != nil || string(b) != expected {
+			t.Errorf("expected %s to load %q, got %q, %v", uri, expected, b, err)
+		}
+	}
+
+	for _, uri := range []string{"https://other.example.com/address.json", "https://schemas.example.com/missing.json"} {
+		if _, err := l.Load(mustParseURL(t, uri)); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected %s not to exist, got %v", uri, err)
+		}
+	}
+}
+
+func TestThatCacheLoaderCachesFetchedDocuments(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/common/address.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"type": "string"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	l := &js_inputs.CacheLoader{Dir: dir, Next: &js_inputs.HTTPLoader{Client: server.Client()}}
+	uri := mustParseURL(t, server.URL+"/common/address.json")
+	for i := 0; i < 2; i++ {
+		b, err := l.Load(uri)
+		if err != nil || string(b) != `{"type": "string"}` {
+			t.Fatalf("unexpected document %q, %v", b, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the document to be fetched once, got %d requests", requests)
+	}
+	if _, err := os.Stat(filepath.Join(dir, uri.Host, "common", "address.json")); err != nil {
+		t.Errorf("expected the document to be cached: %v", err)
+	}
+
+	if _, err := l.Load(mustParseURL(t, server.URL+"/missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing document not to exist, got %v", err)
+	}
+
+	// without a fetcher only the cache is read
+	offline := &js_inputs.CacheLoader{Dir: dir}
+	if _, err := offline.Load(uri); err != nil {
+		t.Errorf("expected the cached document to load offline, got %v", err)
+	}
+	if _, err := offline.Load(mustParseURL(t, server.URL+"/other.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected an uncached document not to exist, got %v", err)
+	}
+}
+
+func TestThatCacheLoaderStaysInItsDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	fetched := js_inputs.LoaderFunc(func(uri *url.URL) ([]byte, error) {
+		return []byte(`{"type": "string"}`), nil
+	})
+	l := &js_inputs.CacheLoader{Dir: dir, Next: fetched}
+
+	for _, host := range []string{"..", ".", `..\..`, "../.."} {
+		uri := &url.URL{Scheme: "https", Host: host, Path: "/escaped.json"}
+		if _, err := l.Load(uri); err == nil {
+			t.Errorf("expected the host %q to be rejected", host)
+		}
+	}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(dir), "*.json"))
+	if len(matches) > 0 {
+		t.Errorf("expected nothing to be written outside the cache, got %v", matches)
+	}
+}
+
+func TestThatHTTPLoaderReportsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := (&js_inputs.HTTPLoader{Client: server.Client()}).Load(mustParseURL(t, server.URL+"/address.json"))
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a server error, got %v", err)
+	}
+}
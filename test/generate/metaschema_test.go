@@ -0,0 +1,115 @@
+package generate
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+// parseSchema parses document, returning its meta-schema violations as the error.
+func parseSchema(name, document string) error {
+	schema, err := js_inputs.ParseSource(name, []byte(document), &url.URL{Scheme: "file", Path: "/" + name}, false)
+	if err == nil && schema.MetaSchemaError != nil {
+		return schema.MetaSchemaError
+	}
+	return err
+}
+
+func TestThatSchemasAreValidatedAgainstTheirMetaSchema(t *testing.T) {
+	for name, test := range map[string]struct {
+		file       string
+		document   string
+		violations []js_inputs.MetaSchemaViolation
+	}{
+		"required": {
+			file: "order.json",
+			document: `{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "type": "object",
+    "properties": {
+        "id": { "type": "string", "required": true }
+    }
+}`,
+			violations: []js_inputs.MetaSchemaViolation{{
+				Pointer: js_inputs.Pointer{"properties", "id", "required"}, Line: 5, Character: 47,
+				Message: "expected array, got boolean",
+			}},
+		},
+		"type": {
+			file:     "order.json",
+			document: `{ "$schema": "http://json-schema.org/draft-04/schema#", "type": "strng" }`,
+			violations: []js_inputs.MetaSchemaViolation{{
+				Pointer: js_inputs.Pointer{"type"}, Line: 1, Character: 65,
+				Message: `"strng" is not one of "array", "boolean", "integer", "null", "number", "object", "string"`,
+			}},
+		},
+		"items": {
+			file: "order.yaml",
+			document: `$schema: https://json-schema.org/draft/2020-12/schema
+type: array
+items:
+  - type: string
+minItems: -1
+`,
+			violations: []js_inputs.MetaSchemaViolation{
+				{Pointer: js_inputs.Pointer{"items"}, Line: 4, Character: 3, Message: "expected object or boolean, got array"},
+				{Pointer: js_inputs.Pointer{"minItems"}, Line: 5, Character: 11, Message: "-1 is less than the minimum 0"},
+			},
+		},
+		"anchor": {
+			file:     "order.json",
+			document: `{ "$schema": "https://json-schema.org/draft/2019-09/schema", "$defs": { "a": { "$anchor": "1a" } } }`,
+			violations: []js_inputs.MetaSchemaViolation{{
+				Pointer: js_inputs.Pointer{"$defs", "a", "$anchor"}, Line: 1, Character: 91,
+				Message: `"1a" doesn't match the pattern ^[A-Za-z][-A-Za-z0-9.:_]*$`,
+			}},
+		},
+	} {
+		err := parseSchema(test.file, test.document)
+		var metaErr *js_inputs.MetaSchemaError
+		if !errors.As(err, &metaErr) {
+			t.Errorf("%s: expected a meta-schema error, got %v", name, err)
+			continue
+		}
+		if metaErr.Name != test.file || !reflect.DeepEqual(metaErr.Violations, test.violations) {
+			t.Errorf("%s: unexpected violations of %s: %+v", name, metaErr.Name, metaErr.Violations)
+		}
+	}
+}
+
+func TestThatValidSchemasAreAccepted(t *testing.T) {
+	for name, document := range map[string]string{
+		"draft-04": `{
+            "$schema": "http://json-schema.org/draft-04/schema#",
+            "type": "object",
+            "required": ["price"],
+            "properties": { "price": { "type": "number", "minimum": 0, "exclusiveMinimum": true } }
+        }`,
+		"draft-06": `{
+            "$schema": "http://json-schema.org/draft-06/schema#",
+            "properties": { "tags": { "type": "array", "items": { "type": "string" }, "contains": { "const": "new" } } },
+            "propertyNames": { "pattern": "^[a-z]+$" }
+        }`,
+		"2019-09": `{
+            "$schema": "https://json-schema.org/draft/2019-09/schema",
+            "$defs": { "name": { "type": "string", "minLength": 1 } },
+            "properties": { "name": { "$ref": "#/$defs/name" } },
+            "dependentRequired": { "name": ["id"] },
+            "unevaluatedProperties": false
+        }`,
+		"2020-12": `{
+            "$schema": "https://json-schema.org/draft/2020-12/schema",
+            "prefixItems": [{ "type": "integer" }],
+            "items": { "$dynamicRef": "#node" },
+            "$defs": { "node": { "$dynamicAnchor": "node", "type": ["object", "null"] } }
+        }`,
+		"unknown meta-schema": `{ "$schema": "https://example.com/schema", "type": "strng" }`,
+	} {
+		if err := parseSchema("order.json", document); err != nil {
+			t.Errorf("%s: expected the schema to be accepted, got %v", name, err)
+		}
+	}
+}
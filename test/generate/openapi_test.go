@@ -0,0 +1,149 @@
+package generate
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestThatOpenAPIDocumentsAreParsed(t *testing.T) {
+	s := `{
+        "openapi": "3.1.0",
+        "info": { "title": "Pets", "version": "1" },
+        "paths": {
+            "/pets/{id}": {
+                "put": {
+                    "requestBody": {
+                        "content": {
+                            "text/plain": { "schema": { "type": "string" } },
+                            "application/merge-patch+json": { "schema": { "$ref": "#/components/schemas/Pet" } }
+                        }
+                    },
+                    "responses": { "204": { "description": "updated" } }
+                }
+            }
+        },
+        "components": {
+            "schemas": {
+                "Pet": {
+                    "type": "object",
+                    "properties": {
+                        "name": { "type": ["string", "null"] },
+                        "kind": {
+                            "anyOf": [ { "$ref": "#/components/schemas/Cat" }, { "$ref": "#/components/schemas/Dog" } ],
+                            "discriminator": { "propertyName": "type", "mapping": { "kitten": "Cat" } }
+                        }
+                    }
+                },
+                "Cat": { "type": "object" },
+                "Dog": { "type": "object" }
+            }
+        }
+    }`
+	uri := &url.URL{Scheme: "file", Path: "/api.json"}
+	so, err := js_inputs.ParseSource("api.json", []byte(s), uri, true)
+	if err != nil {
+		t.Fatal("It was not possible to parse the OpenAPI document:", err)
+	}
+	if so.OpenAPI != "3.1.0" || so.Title != "Pets" || len(so.Components) != 3 {
+		t.Errorf("unexpected document %q %q with %d components", so.OpenAPI, so.Title, len(so.Components))
+	}
+
+	name := so.Components["Pet"].Properties["name"]
+	if name.TypeValue != "string" || !name.Nullable {
+		t.Errorf("expected a nullable string, got %v", name.TypeValue)
+	}
+	expected := map[string]string{
+		"kitten": "#/components/schemas/Cat",
+		"Dog":    "#/components/schemas/Dog",
+	}
+	if mapping := so.Components["Pet"].Properties["kind"].Discriminator.Mapping; !reflect.DeepEqual(mapping, expected) {
+		t.Errorf("expected the mapping %v, got %v", expected, mapping)
+	}
+
+	if len(so.Bodies) != 1 || so.Bodies[0].Name != "PutPetsIdRequest" {
+		t.Fatalf("expected the request body only, got %v", so.Bodies)
+	}
+	resolver := js_inputs.NewRefResolver([]*js_inputs.Schema{so})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if path := resolver.GetPath(so.Bodies[0].Schema); path != "#/paths/~1pets~1{id}/put/requestBody/content/application~1merge-patch+json/schema" {
+		t.Errorf("unexpected path %s", path)
+	}
+	resolved, err := resolver.GetSchemaByReference(&js_inputs.Schema{
+		Reference: "#/paths/~1pets~1%7Bid%7D/put/requestBody/content/application~1merge-patch+json/schema",
+		Parent:    so,
+	})
+	if err != nil || resolved != so.Bodies[0].Schema {
+		t.Errorf("expected the pointer to resolve to the body, got %v", err)
+	}
+}
+
+func TestThatUnsupportedOpenAPIDocumentsAreRejected(t *testing.T) {
+	for name, test := range map[string]struct {
+		document string
+		expected string
+	}{
+		"swagger": {
+			document: `{ "swagger": "2.0", "info": { "title": "Pets", "version": "1" } }`,
+			expected: "swagger 2.0 documents are not supported",
+		},
+		"version": {
+			document: `{ "openapi": "4.0.0", "info": { "title": "Pets", "version": "1" } }`,
+			expected: `unsupported OpenAPI version "4.0.0"`,
+		},
+		"discriminator": {
+			document: `{ "openapi": "3.0.3", "components": { "schemas": { "Pet": { "discriminator": {} } } } }`,
+			expected: "discriminator without a propertyName at file:///api.json#/components/schemas/Pet",
+		},
+	} {
+		_, err := js_inputs.ParseSource("api.json", []byte(test.document), &url.URL{Scheme: "file", Path: "/api.json"}, true)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, test.expected, err)
+		}
+	}
+}
+
+func TestThatReadOnlyAndWriteOnlyPropertiesAreNotRequired(t *testing.T) {
+	so, err := js_inputs.ParseSource("api.json", []byte(`{
+        "openapi": "3.0.3",
+        "components": {
+            "schemas": {
+                "Account": {
+                    "type": "object",
+                    "required": ["id", "password", "email"],
+                    "properties": {
+                        "id": { "type": "string", "readOnly": true },
+                        "password": { "type": "string", "writeOnly": true },
+                        "email": { "type": "string" },
+                        "nickname": { "type": "string", "nullable": true, "x-go-pointer": false }
+                    }
+                }
+            }
+        }
+    }`), &url.URL{Scheme: "file", Path: "/api.json"}, true)
+	if err != nil {
+		t.Fatal("It was not possible to parse the OpenAPI document:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+	account, ok := g.Structs["Account"]
+	if !ok {
+		t.Fatalf("expected an Account struct, got %v", g.Structs)
+	}
+	for field, required := range map[string]bool{"Id": false, "Password": false, "Email": true} {
+		if account.Fields[field].Required != required {
+			t.Errorf("expected %s to be required: %v", field, required)
+		}
+	}
+	if p := account.Fields["Nickname"].Pointer; p == nil || *p {
+		t.Error("expected x-go-pointer to override nullable")
+	}
+}
@@ -1,6 +1,9 @@
 package generate
 
 import (
+	"bytes"
+	"go/format"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -91,3 +94,144 @@ func TestLineAndCharacterFromOffset(t *testing.T) {
 		}
 	}
 }
+
+func TestThatStructFieldsFollowSchemaOrder(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/schema#",
+        "title": "Ordered",
+        "type": "object",
+        "properties": {
+            "zulu": { "type": "string" },
+            "alpha": { "type": "string" },
+            "mike": { "type": "integer" }
+        },
+        "required": [ "mike" ]
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "output_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	tests := []struct {
+		alphabetical bool
+		expected     []string
+	}{
+		{
+			alphabetical: false,
+			expected:     []string{"Zulu ", "Alpha ", "Mike ", `"zulu"`, `"alpha"`, `"mike"`},
+		},
+		{
+			alphabetical: true,
+			expected:     []string{"Alpha ", "Mike ", "Zulu ", `"alpha"`, `"mike"`, `"zulu"`},
+		},
+	}
+
+	for _, test := range tests {
+		buf := new(bytes.Buffer)
+		err := js_inputs.OutputWithOptions(buf, g, "test", nil, js_inputs.OutputOptions{AlphabeticalFields: test.alphabetical})
+		if err != nil {
+			t.Fatal("Failed to output code:", err)
+		}
+
+		// the marshal code is written after the struct, so each expected string must follow the previous one
+		code := buf.String()
+		pos := 0
+		for _, e := range test.expected {
+			idx := strings.Index(code[pos:], e)
+			if idx < 0 {
+				t.Errorf("alphabetical=%v: expected to find %s after position %d in:\n%s", test.alphabetical, e, pos, code)
+				break
+			}
+			pos += idx + len(e)
+		}
+	}
+}
+
+func TestThatGeneratedCodeIsFormattedWithGroupedImports(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/schema#",
+        "title": "Payment",
+        "properties": {
+            "amount": { "$ref": "#/definitions/money" },
+            "at": { "type": "string", "x-go-type": "time.Time", "x-go-type-import": "time" }
+        },
+        "required": ["amount"],
+        "definitions": {
+            "money": { "type": "object", "x-go-type": "money.Amount", "x-go-type-import": "github.com/acme/money" }
+        }
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "output_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	var outputs []string
+	for i := 0; i < 5; i++ {
+		buf := new(bytes.Buffer)
+		if err := js_inputs.Output(buf, g, "test", nil, false); err != nil {
+			t.Fatal("Failed to output code:", err)
+		}
+		outputs = append(outputs, buf.String())
+	}
+
+	code := outputs[0]
+	for _, o := range outputs[1:] {
+		if o != code {
+			t.Fatalf("expected the output to be the same on every run")
+		}
+	}
+
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatal("Failed to format the output:", err)
+	}
+	if string(formatted) != code {
+		t.Errorf("expected the output to be gofmt formatted, got:\n%s", code)
+	}
+
+	expected := "import (\n\t\"bytes\"\n\t\"encoding/json\"\n\t\"errors\"\n\t\"fmt\"\n\t\"time\"\n\n\t\"github.com/acme/money\"\n)\n"
+	if !strings.Contains(code, expected) {
+		t.Errorf("expected sorted and grouped imports, got:\n%s", code)
+	}
+}
+
+func TestThatCodeWhichDoesNotParseIsReportedAgainstItsType(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/schema#",
+        "title": "Invoice",
+        "properties": {
+            "lines": { "type": "array", "x-go-type": "[]Line{" }
+        }
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "output_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	buf := new(bytes.Buffer)
+	err = js_inputs.Output(buf, g, "test", nil, false)
+	if err == nil {
+		t.Fatalf("expected an error for code which does not parse")
+	}
+	if !strings.Contains(err.Error(), "type Invoice") {
+		t.Errorf("expected the error to refer to type Invoice, got: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,111 @@
+package generate
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func parsePackageSchemas(t *testing.T, docs map[string]string) []*js_inputs.Schema {
+	var schemas []*js_inputs.Schema
+	for id, doc := range docs {
+		s, err := js_inputs.Parse(doc, &url.URL{Scheme: "file", Path: "/schemas/" + id})
+		if err != nil {
+			t.Fatal("It was not possible to unmarshal the schema:", err)
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas
+}
+
+func TestThatTypesArePlacedIntoMappedPackages(t *testing.T) {
+	schemas := parsePackageSchemas(t, map[string]string{
+		"common/address.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Address",
+            "type": "object",
+            "properties": { "street": { "type": "string" } }
+        }`,
+		"orders/order.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Address",
+            "type": "object",
+            "properties": { "delivery": { "$ref": "../common/address.json" }, "code": { "type": "integer" } }
+        }`,
+	})
+
+	g := js_inputs.NewWithOptions(js_inputs.GeneratorOptions{
+		Packages: []js_inputs.PackageMapping{
+			{Prefix: "file:///schemas/common/", ImportPath: "github.com/acme/models/common"},
+		},
+	}, schemas...)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	// both types are called Address, but they live in different packages so neither needs renaming
+	order, ok := g.Structs["Address"]
+	if !ok {
+		t.Fatalf("expected Address in the default package, got %v", getStructNamesFromMap(g.Structs))
+	}
+	if _, ok := g.Structs["github.com/acme/models/common.Address"]; !ok {
+		t.Fatalf("expected Address in the common package, got %v", getStructNamesFromMap(g.Structs))
+	}
+	if order.Fields["Delivery"].Type.Package == nil || order.Fields["Delivery"].Type.Package.PackageName() != "common" {
+		t.Errorf("expected Delivery to refer to the common package")
+	}
+}
+
+func TestThatImportCyclesBetweenPackagesAreReported(t *testing.T) {
+	schemas := parsePackageSchemas(t, map[string]string{
+		"a/a.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "A",
+            "type": "object",
+            "properties": { "b": { "$ref": "../b/b.json" } }
+        }`,
+		"b/b.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "B",
+            "type": "object",
+            "properties": { "a": { "$ref": "../a/a.json" } }
+        }`,
+	})
+
+	g := js_inputs.NewWithOptions(js_inputs.GeneratorOptions{
+		Packages: []js_inputs.PackageMapping{
+			{Prefix: "file:///schemas/a/", ImportPath: "example.com/a"},
+			{Prefix: "file:///schemas/b/", ImportPath: "example.com/b"},
+		},
+	}, schemas...)
+	err := g.CreateTypes()
+	if err == nil {
+		t.Fatal("expected an import cycle error")
+	}
+	if !strings.Contains(err.Error(), "import cycle between packages example.com/a -> example.com/b -> example.com/a") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.Contains(err.Error(), "A.B refers to B") || !strings.Contains(err.Error(), "B.A refers to A") {
+		t.Errorf("expected the error to name the types involved: %v", err)
+	}
+}
+
+func TestParsePackageMappings(t *testing.T) {
+	mappings, err := js_inputs.ParsePackageMappings("https://schemas.example.com/common/=github.com/acme/models/common:./out/common")
+	if err != nil {
+		t.Fatal("Failed to parse the package mappings:", err)
+	}
+	expected := js_inputs.PackageMapping{
+		Prefix:     "https://schemas.example.com/common/",
+		ImportPath: "github.com/acme/models/common",
+		Dir:        "./out/common",
+	}
+	if len(mappings) != 1 || mappings[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, mappings)
+	}
+	if mappings[0].PackageName() != "common" {
+		t.Errorf("expected the package name common, got %s", mappings[0].PackageName())
+	}
+}
@@ -0,0 +1,109 @@
+package generate
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestThatJSONPointersAreParsedAndEscaped(t *testing.T) {
+	for s, expected := range map[string]js_inputs.Pointer{
+		"":                         {},
+		"/":                        {""},
+		"/properties/a~1b":         {"properties", "a/b"},
+		"/definitions/m~0n/items":  {"definitions", "m~n", "items"},
+		"/definitions/~01":         {"definitions", "~1"},
+		"/properties/with space/ü": {"properties", "with space", "ü"},
+	} {
+		p, err := js_inputs.ParsePointer(s)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", s, err)
+			continue
+		}
+		if !reflect.DeepEqual(p, expected) {
+			t.Errorf("expected %q to parse to %q, got %q", s, expected, p)
+		}
+		if p.String() != s {
+			t.Errorf("expected %q to format as %q, got %q", expected, s, p.String())
+		}
+	}
+
+	for _, invalid := range []string{"properties", "/a~", "/a~2b"} {
+		if _, err := js_inputs.ParsePointer(invalid); err == nil {
+			t.Errorf("expected an error parsing %q", invalid)
+		}
+	}
+
+	fragment := js_inputs.Pointer{"properties", "a/b c", "é"}.Fragment()
+	if fragment != "#/properties/a~1b%20c/%C3%A9" {
+		t.Errorf("unexpected fragment %s", fragment)
+	}
+}
+
+func TestThatReferencesWithEscapedPointersResolve(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Escapes",
+        "type": "object",
+        "properties": {
+            "slash": { "$ref": "#/definitions/street~1name" },
+            "tilde": { "$ref": "#/definitions/m~0n" },
+            "space": { "$ref": "#/definitions/with%20space" },
+            "encoded": { "$ref": "#/definitions/caf%C3%A9" },
+            "unicode": { "$ref": "#/definitions/café" },
+            "nested": { "$ref": "#/definitions/street~1name/properties/a~1b" }
+        },
+        "definitions": {
+            "street/name": { "type": "object", "properties": { "a/b": { "type": "string" } } },
+            "m~n": { "type": "integer" },
+            "with space": { "type": "boolean" },
+            "café": { "type": "number" }
+        }
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "/pointer_test.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	resolver := js_inputs.NewRefResolver([]*js_inputs.Schema{so})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for property, expected := range map[string]*js_inputs.Schema{
+		"slash":   so.Definitions["street/name"],
+		"tilde":   so.Definitions["m~n"],
+		"space":   so.Definitions["with space"],
+		"encoded": so.Definitions["café"],
+		"unicode": so.Definitions["café"],
+		"nested":  so.Definitions["street/name"].Properties["a/b"],
+	} {
+		resolved, err := resolver.GetSchemaByReference(so.Properties[property])
+		if err != nil {
+			t.Errorf("unexpected error resolving %s: %v", property, err)
+			continue
+		}
+		if resolved != expected {
+			t.Errorf("expected %s to resolve to %s", property, so.Properties[property].Reference)
+		}
+	}
+
+	if path := resolver.GetPath(so.Definitions["street/name"].Properties["a/b"]); path != "#/definitions/street~1name/properties/a~1b" {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	so.Properties["slash"].Reference = "#/definitions/street/name"
+	if _, err := resolver.GetSchemaByReference(so.Properties["slash"]); err == nil {
+		t.Error("expected an unescaped slash not to resolve")
+	}
+
+	g := js_inputs.New(so)
+	so.Properties["slash"].Reference = "#/definitions/street~1name"
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+	if _, ok := g.Structs["StreetName"]; !ok {
+		t.Errorf("expected a StreetName struct, got %v", g.Structs)
+	}
+}
@@ -0,0 +1,177 @@
+package generate
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestNamingConventions(t *testing.T) {
+	tests := []struct {
+		input    string
+		naming   js_inputs.NamingConvention
+		expected string
+	}{
+		{input: "houseNumber", naming: js_inputs.NamingAsIs, expected: "houseNumber"},
+		{input: "houseNumber", naming: js_inputs.NamingSnake, expected: "house_number"},
+		{input: "houseNumber", naming: js_inputs.NamingKebab, expected: "house-number"},
+		{input: "house_number", naming: js_inputs.NamingCamel, expected: "houseNumber"},
+		{input: "HTTPServer", naming: js_inputs.NamingSnake, expected: "http_server"},
+		{input: "address line 1", naming: js_inputs.NamingCamel, expected: "addressLine1"},
+		{input: "line1Text", naming: js_inputs.NamingKebab, expected: "line1-text"},
+	}
+
+	for idx, test := range tests {
+		actual := js_inputs.ApplyNamingConvention(test.input, test.naming)
+		if actual != test.expected {
+			t.Errorf("Test %d failed: For input \"%s\" (%s), expected \"%s\", got \"%s\"", idx, test.input, test.naming, test.expected, actual)
+		}
+	}
+}
+
+func TestParseTagOptions(t *testing.T) {
+	tags, err := js_inputs.ParseTagOptions("yaml:snake, bson:camel,db")
+	if err != nil {
+		t.Fatal("Failed to parse tags:", err)
+	}
+
+	expected := []js_inputs.TagOption{
+		{Name: "yaml", Naming: js_inputs.NamingSnake},
+		{Name: "bson", Naming: js_inputs.NamingCamel},
+		{Name: "db", Naming: js_inputs.NamingAsIs},
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %+v, got %+v", expected, tags)
+	}
+
+	if _, err := js_inputs.ParseTagOptions("yaml:shouty"); err == nil {
+		t.Error("expected an error for an unknown naming convention")
+	}
+	if _, err := js_inputs.ParseTagOptions("yaml,validate"); err != nil {
+		t.Error("expected the validate tag to be accepted:", err)
+	}
+}
+
+func TestThatConfiguredTagsAreEmitted(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/schema#",
+        "title": "Tagged",
+        "type": "object",
+        "properties": {
+            "houseNumber": { "type": "integer" },
+            "postCode": {
+                "type": "string",
+                "x-go-tags": { "db": "post_code_id", "validate": "required" }
+            }
+        },
+        "required": [ "houseNumber" ]
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "tags_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	buf := new(bytes.Buffer)
+	err = js_inputs.OutputWithOptions(buf, g, "test", nil, js_inputs.OutputOptions{
+		Tags: []js_inputs.TagOption{
+			{Name: "yaml", Naming: js_inputs.NamingSnake},
+			{Name: "validate"},
+			{Name: "db", Naming: js_inputs.NamingSnake},
+		},
+	})
+	if err != nil {
+		t.Fatal("Failed to output code:", err)
+	}
+
+	// the validate tag is only written where x-go-tags sets it, since a property name isn't a validator
+	expected := []string{
+		"`json:\"houseNumber\" yaml:\"house_number\" db:\"house_number\"`",
+		"`json:\"postCode,omitempty\" yaml:\"post_code,omitempty\" validate:\"required\" db:\"post_code_id\"`",
+	}
+	for _, e := range expected {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("expected to find %s in:\n%s", e, buf.String())
+		}
+	}
+
+	// the validate tags derived from the constraints would replace the configured ones
+	err = js_inputs.OutputWithOptions(new(bytes.Buffer), g, "test", nil, js_inputs.OutputOptions{
+		Tags:         []js_inputs.TagOption{{Name: "validate"}},
+		ValidateTags: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "the tag 'validate' of Tags (-tags) conflicts with ValidateTags (-validate-tags)") {
+		t.Errorf("expected the validate tag to conflict with ValidateTags, got %v", err)
+	}
+}
+
+func TestThatTagValuesAreQuoted(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/schema#",
+        "title": "Tagged",
+        "type": "object",
+        "properties": {
+            "postCode": { "type": "string", "x-go-tags": { "db": "name=\"post_code\"" } }
+        }
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "/tags_test.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := js_inputs.Output(buf, g, "test", nil, false); err != nil {
+		t.Fatal("Failed to output code:", err)
+	}
+
+	e := "`json:\"postCode,omitempty\" db:\"name=\\\"post_code\\\"\"`"
+	if !strings.Contains(buf.String(), e) {
+		t.Errorf("expected to find %s in:\n%s", e, buf.String())
+	}
+	if db := reflect.StructTag(strings.Trim(e, "`")).Get("db"); db != `name="post_code"` {
+		t.Errorf("expected the db tag to read back as written, got %q", db)
+	}
+}
+
+func TestThatTagsWhichCannotBeWrittenAreRejected(t *testing.T) {
+	tests := []struct {
+		tags     string
+		expected string
+	}{
+		{
+			tags:     `{ "json": "nom" }`,
+			expected: `x-go-tags at "file:///tags_test.json#/properties/name" can't set the json tag, which is the property name; set x-go-name to rename the field`,
+		},
+		{
+			tags:     "{ \"db\": \"a`b\" }",
+			expected: `x-go-tags at "file:///tags_test.json#/properties/name": the value of the db tag can't contain a backquote`,
+		},
+		{
+			tags:     `{ "db id": "x" }`,
+			expected: `x-go-tags at "file:///tags_test.json#/properties/name": invalid tag name "db id"`,
+		},
+	}
+
+	for _, test := range tests {
+		s := `{ "$schema": "http://json-schema.org/schema#", "title": "Tagged", "type": "object", "properties": { "name": { "type": "string", "x-go-tags": ` + test.tags + ` } } }`
+		so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "/tags_test.json"})
+		if err != nil {
+			t.Fatal("It was not possible to unmarshal the schema:", err)
+		}
+		err = js_inputs.New(so).CreateTypes()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("For the tags %s, expected the error %q, got %v", test.tags, test.expected, err)
+		}
+	}
+}
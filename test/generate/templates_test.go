@@ -0,0 +1,93 @@
+package generate
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func templateGenerator(t *testing.T) *js_inputs.Generator {
+	s := `{
+        "$schema": "http://json-schema.org/schema#",
+        "title": "Order",
+        "properties": {
+            "id": { "type": "string" },
+            "quantity": { "type": "integer" }
+        },
+        "required": ["id"]
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "templates_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+	return g
+}
+
+func writeTemplates(t *testing.T, templates map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestThatTemplatesCanBeOverridden(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"validate.tmpl": `{{- import "strings" -}}
+func (strct *{{.Name}}) Validate() []error {
+	{{- range .Fields}}{{if .Required}}
+	if strings.TrimSpace(strct.{{.Name}}) == "" {
+		return []error{ {{template "requiredError" .}} }
+	}
+	{{- end}}{{end}}
+	return nil
+}
+`,
+		"errors.tmpl": `{{define "requiredError"}}ErrFieldRequired{{end}}`,
+	})
+
+	buf := new(bytes.Buffer)
+	err := js_inputs.OutputWithOptions(buf, templateGenerator(t), "test", nil, js_inputs.OutputOptions{TemplateDir: dir})
+	if err != nil {
+		t.Fatal("Failed to output code:", err)
+	}
+
+	code := buf.String()
+	for _, expected := range []string{
+		`if strings.TrimSpace(strct.Id) == "" {`,
+		"return []error{ErrFieldRequired}",
+		"\t\"strings\"\n",
+		// the other templates are unchanged
+		"func (strct *Order) UnmarshalJSON(b []byte) error {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("expected the output to contain %q, got:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "allErrors") {
+		t.Errorf("expected the default Validate method to be replaced, got:\n%s", code)
+	}
+}
+
+func TestThatInvalidTemplatesAreReported(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"struct.tmpl": `type {{.Name}} struct {{range .Fields}}`,
+	})
+
+	err := js_inputs.OutputWithOptions(new(bytes.Buffer), templateGenerator(t), "test", nil, js_inputs.OutputOptions{TemplateDir: dir})
+	if err == nil || !strings.Contains(err.Error(), "struct.tmpl") {
+		t.Errorf("expected an error referring to struct.tmpl, got %v", err)
+	}
+}
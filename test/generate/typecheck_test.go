@@ -0,0 +1,66 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func typeCheckSchemas(t *testing.T, doc string) error {
+	g := js_inputs.New(parsePackageSchemas(t, map[string]string{"invoice.json": doc})...)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	files, err := js_inputs.OutputFiles(g, nil, "models", nil, js_inputs.OutputOptions{})
+	if err != nil {
+		t.Fatal("Failed to output files:", err)
+	}
+	return js_inputs.TypeCheck(g, []js_inputs.GeneratedPackage{{Name: "models", Dir: t.TempDir(), Files: files}})
+}
+
+func TestThatGeneratedCodeTypeChecks(t *testing.T) {
+	err := typeCheckSchemas(t, `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Invoice",
+        "type": "object",
+        "properties": {
+            "lines": { "type": "array", "items": { "$ref": "#/definitions/line" } },
+            "issued": { "type": "string", "x-go-type": "time.Time", "x-go-type-import": "time" }
+        },
+        "required": ["lines"],
+        "additionalProperties": { "type": "integer" },
+        "definitions": {
+            "line": { "type": "object", "properties": { "amount": { "type": "number" } } }
+        }
+    }`)
+	if err != nil {
+		t.Errorf("expected the generated code to type check, got: %v", err)
+	}
+}
+
+func TestThatCompileErrorsAreReportedAgainstTheirSchema(t *testing.T) {
+	err := typeCheckSchemas(t, `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Invoice",
+        "type": "object",
+        "properties": {
+            "lines": { "type": "array", "items": { "$ref": "#/definitions/line" } }
+        },
+        "definitions": {
+            "line": {
+                "type": "object",
+                "properties": { "amount": { "type": "number", "x-go-type": "Money" } }
+            }
+        }
+    }`)
+	if err == nil {
+		t.Fatalf("expected a compile error for the undefined Money type")
+	}
+	for _, expected := range []string{"undefined: Money", "type Line generated from file:///schemas/invoice.json#/definitions/line"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected the error to contain %q, got: %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package generate
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestParseQualifiedType(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedType   string
+		expectedImport string
+	}{
+		{input: "github.com/acme/money.Amount", expectedType: "money.Amount", expectedImport: "github.com/acme/money"},
+		{input: "*github.com/acme/geo.Point", expectedType: "*geo.Point", expectedImport: "github.com/acme/geo"},
+		{input: "[]github.com/acme/geo/v2.Point", expectedType: "[]geo.Point", expectedImport: "github.com/acme/geo/v2"},
+		{input: "gopkg.in/yaml.v3.Node", expectedType: "yaml.Node", expectedImport: "gopkg.in/yaml.v3"},
+		{input: "time.Time", expectedType: "time.Time", expectedImport: "time"},
+		{input: "string", expectedType: "string", expectedImport: ""},
+		{input: "github.com/acme/go-money;money.Amount", expectedType: "money.Amount", expectedImport: "github.com/acme/go-money"},
+		{input: "*example.com/v2;geo.Point", expectedType: "*geo.Point", expectedImport: "example.com/v2"},
+	}
+
+	for idx, test := range tests {
+		actualType, actualImport, err := js_inputs.ParseQualifiedType(test.input)
+		if err != nil || actualType != test.expectedType || actualImport != test.expectedImport {
+			t.Errorf("Test %d failed: For input \"%s\", expected \"%s\" from \"%s\", got \"%s\" from \"%s\" (%v)",
+				idx, test.input, test.expectedType, test.expectedImport, actualType, actualImport, err)
+		}
+	}
+}
+
+func TestThatInvalidPackageNamesAreRejected(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "github.com/acme/go-money.Amount", expected: `the package name "go-money" guessed from the import path "github.com/acme/go-money" is not a go identifier, name the package after a semicolon, e.g. "github.com/acme/go-money;<name>.Amount"`},
+		{input: "example.com/v2.Point", expected: `the package name "example.com" guessed from the import path "example.com/v2"`},
+		{input: "github.com/acme/go-money;go-money.Amount", expected: `the package name "go-money" of "github.com/acme/go-money;go-money.Amount" is not a go identifier`},
+		{input: ";money.Amount", expected: `invalid go type ";money.Amount"`},
+	}
+
+	for _, test := range tests {
+		if _, _, err := js_inputs.ParseQualifiedType(test.input); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("For input \"%s\", expected an error containing %q, got %v", test.input, test.expected, err)
+		}
+	}
+	if _, err := js_inputs.ParseTypeMap("common.json=github.com/acme/go-money.Amount"); err == nil {
+		t.Error("expected an error for a type mapping whose package name can't be guessed")
+	}
+}
+
+func TestParseTypeMap(t *testing.T) {
+	typeMap, err := js_inputs.ParseTypeMap("common.json#/definitions/money=github.com/acme/money.Amount, geo.json=*github.com/acme/geo.Point")
+	if err != nil {
+		t.Fatal("Failed to parse the type map:", err)
+	}
+	if typeMap["common.json#/definitions/money"] != "github.com/acme/money.Amount" || typeMap["geo.json"] != "*github.com/acme/geo.Point" {
+		t.Errorf("unexpected type map %v", typeMap)
+	}
+
+	if _, err := js_inputs.ParseTypeMap("common.json#/definitions/money"); err == nil {
+		t.Error("expected an error for a mapping without a go type")
+	}
+}
+
+func TestThatMappedReferencesUseExistingTypes(t *testing.T) {
+	common, err := js_inputs.Parse(`{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "definitions": {
+            "money": {
+                "type": "object",
+                "properties": { "amount": { "type": "integer" }, "currency": { "type": "string" } }
+            }
+        }
+    }`, &url.URL{Scheme: "file", Path: "/schemas/common.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+	order, err := js_inputs.Parse(`{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Order",
+        "type": "object",
+        "properties": {
+            "total": { "$ref": "common.json#/definitions/money" },
+            "lines": { "type": "array", "items": { "$ref": "common.json#/definitions/money" } }
+        }
+    }`, &url.URL{Scheme: "file", Path: "/schemas/order.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.NewWithOptions(js_inputs.GeneratorOptions{
+		TypeMap: map[string]string{"common.json#/definitions/money": "github.com/acme/money.Amount"},
+	}, common, order)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	if _, ok := g.Structs["Money"]; ok {
+		t.Error("expected the mapped Money definition not to be generated")
+	}
+	testField(g.Structs["Order"].Fields["Total"], "total", "Total", "money.Amount", false, t)
+	testField(g.Structs["Order"].Fields["Lines"], "lines", "Lines", "[]money.Amount", false, t)
+
+	buf := new(bytes.Buffer)
+	if err := js_inputs.Output(buf, g, "test", nil, false); err != nil {
+		t.Fatal("Failed to output code:", err)
+	}
+	if !strings.Contains(buf.String(), `"github.com/acme/money"`) {
+		t.Errorf("expected the money package to be imported in:\n%s", buf.String())
+	}
+}
+
+func TestThatUnmatchedTypeMappingsAreRejected(t *testing.T) {
+	order, err := js_inputs.Parse(`{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Order",
+        "type": "object",
+        "definitions": {
+            "money": { "type": "object", "properties": { "amount": { "type": "integer" } } }
+        },
+        "properties": {
+            "total": { "$ref": "#/definitions/money" }
+        }
+    }`, &url.URL{Scheme: "file", Path: "/schemas/order.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	// a typo in the key would otherwise generate the type the mapping was meant to replace
+	g := js_inputs.NewWithOptions(js_inputs.GeneratorOptions{
+		TypeMap: map[string]string{
+			"order.json#/definitions/money":  "github.com/acme/money.Amount",
+			"order.json#/definitions/monies": "github.com/acme/money.Amount",
+			"common.json":                    "github.com/acme/common.Common",
+		},
+	}, order)
+	err = g.CreateTypes()
+	expected := "no schema matches the type mapping of 'common.json', 'order.json#/definitions/monies'"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected the error %q, got %v", expected, err)
+	}
+}
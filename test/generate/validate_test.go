@@ -0,0 +1,153 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestThatValidateTagsAreDerivedFromConstraints(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Account",
+        "type": "object",
+        "definitions": {
+            "age": { "type": "integer", "minimum": 18, "exclusiveMaximum": 130 }
+        },
+        "properties": {
+            "name": { "type": "string", "minLength": 1, "maxLength": 40 },
+            "email": { "type": "string", "format": "email" },
+            "age": { "$ref": "#/definitions/age" },
+            "plan": { "type": "string", "enum": [ "free", "pro" ] },
+            "code": { "type": "string", "pattern": "^[A-Z]{3}$" },
+            "colour": { "type": "string", "format": "color" },
+            "nickname": { "type": "string" },
+            "legacy": { "type": "string", "minLength": 2, "x-go-tags": { "validate": "-" } }
+        },
+        "required": [ "name", "email" ]
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "validate_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	var warnings []string
+	buf := new(bytes.Buffer)
+	err = js_inputs.OutputWithOptions(buf, g, "test", nil, js_inputs.OutputOptions{
+		ValidateTags: true,
+		Warnf: func(format string, args ...interface{}) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	})
+	if err != nil {
+		t.Fatal("Failed to output code:", err)
+	}
+
+	expected := []string{
+		"`json:\"name\" validate:\"min=1,max=40\"`",
+		"`json:\"email\" validate:\"email\"`",
+		"`json:\"age,omitempty\" validate:\"omitempty,gte=18,lt=130\"`",
+		"`json:\"plan,omitempty\" validate:\"omitempty,oneof=free pro\"`",
+		"`json:\"code,omitempty\"`",
+		"`json:\"nickname,omitempty\"`",
+		"`json:\"legacy,omitempty\" validate:\"-\"`",
+	}
+	for _, e := range expected {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("expected to find %s in:\n%s", e, buf.String())
+		}
+	}
+
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %d: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[0], "Account.Code") || !strings.Contains(warnings[0], "pattern") {
+		t.Errorf("expected a pattern warning for Account.Code, got %s", warnings[0])
+	}
+	if !strings.Contains(warnings[1], "Account.Colour") || !strings.Contains(warnings[1], "format") {
+		t.Errorf("expected a format warning for Account.Colour, got %s", warnings[1])
+	}
+}
+
+func TestThatOnlyFieldsWhichCanBeMissingAreRequired(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Switch",
+        "type": "object",
+        "properties": {
+            "enabled": { "type": "boolean" },
+            "count": { "type": "integer", "minimum": 0 },
+            "label": { "type": "string" },
+            "tags": { "type": "array", "items": { "type": "string" } },
+            "owner": { "type": "object", "title": "Owner", "properties": { "name": { "type": "string" } } }
+        },
+        "required": [ "enabled", "count", "label", "tags", "owner" ]
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "validate_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := js_inputs.OutputWithOptions(buf, g, "test", nil, js_inputs.OutputOptions{ValidateTags: true}); err != nil {
+		t.Fatal("Failed to output code:", err)
+	}
+
+	// false, 0 and "" are valid values of the required scalars, which the required rule would reject
+	expected := []string{
+		"`json:\"enabled\"`",
+		"`json:\"count\" validate:\"gte=0\"`",
+		"`json:\"label\"`",
+		"`json:\"tags\" validate:\"required\"`",
+		"`json:\"owner\" validate:\"required\"`",
+	}
+	for _, e := range expected {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("expected to find %s in:\n%s", e, buf.String())
+		}
+	}
+}
+
+func TestThatDraft04ExclusiveBoundsAreSupported(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/draft-04/schema#",
+        "title": "Measurement",
+        "type": "object",
+        "properties": {
+            "ratio": { "type": "number", "minimum": 0, "exclusiveMinimum": true, "maximum": 1.5 }
+        }
+    }`
+	so, err := js_inputs.Parse(s, &url.URL{Scheme: "file", Path: "validate_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types:", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := js_inputs.OutputWithOptions(buf, g, "test", nil, js_inputs.OutputOptions{ValidateTags: true}); err != nil {
+		t.Fatal("Failed to output code:", err)
+	}
+
+	expected := "`json:\"ratio,omitempty\" validate:\"omitempty,gt=0,lte=1.5\"`"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected to find %s in:\n%s", expected, buf.String())
+	}
+}
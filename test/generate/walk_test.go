@@ -0,0 +1,222 @@
+package generate
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+const walkSchema = `{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "title": "Shape",
+    "type": "object",
+    "properties": {
+        "size": { "type": "integer" },
+        "corners": { "type": "array", "items": { "$ref": "#/anyOf/0" } }
+    },
+    "definitions": {
+        "colour": { "not": { "type": "null" } }
+    },
+    "anyOf": [
+        { "type": "object", "properties": { "x": { "type": "number" } } },
+        { "type": "string" }
+    ],
+    "oneOf": [
+        { "$id": "#circle", "type": "object", "properties": { "radius": { "type": "number" } } }
+    ],
+    "if": { "required": ["size"] },
+    "then": { "properties": { "label": { "$ref": "#circle" } } },
+    "else": { "additionalProperties": false }
+}`
+
+func TestThatWalkVisitsEverySubschema(t *testing.T) {
+	so, err := js_inputs.Parse(walkSchema, &url.URL{Scheme: "file", Path: "/walk_test.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	var pointers []string
+	err = so.Walk(func(pointer string, schema *js_inputs.Schema) error {
+		pointers = append(pointers, pointer)
+		if pointer != "" && schema.Parent == nil {
+			t.Errorf("expected the schema at %s to have a parent", pointer)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"",
+		"/definitions/colour",
+		"/definitions/colour/not",
+		"/properties/corners",
+		"/properties/corners/items",
+		"/properties/size",
+		"/anyOf/0",
+		"/anyOf/0/properties/x",
+		"/anyOf/1",
+		"/oneOf/0",
+		"/oneOf/0/properties/radius",
+		"/if",
+		"/then",
+		"/then/properties/label",
+		"/else",
+		"/else/additionalProperties",
+	}
+	if !reflect.DeepEqual(pointers, expected) {
+		t.Errorf("expected the pointers\n%v\ngot\n%v", expected, pointers)
+	}
+
+	if name := so.AnyOf[0].Properties["x"].JSONKey; name != "x" {
+		t.Errorf("expected the property in anyOf to have the key x, got %q", name)
+	}
+}
+
+func TestThatWalkCanSkipSubschemas(t *testing.T) {
+	so, err := js_inputs.Parse(walkSchema, &url.URL{Scheme: "file", Path: "/walk_test.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	var pointers []string
+	err = so.Walk(func(pointer string, schema *js_inputs.Schema) error {
+		pointers = append(pointers, pointer)
+		if pointer != "" {
+			return js_inputs.SkipSubschemas
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pointers) != 10 {
+		t.Errorf("expected the root and its 9 subschemas to be visited, got %v", pointers)
+	}
+}
+
+func TestThatReferencesIntoCombinatorsResolve(t *testing.T) {
+	so, err := js_inputs.Parse(walkSchema, &url.URL{Scheme: "file", Path: "/walk_test.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	resolver := js_inputs.NewRefResolver([]*js_inputs.Schema{so})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	corner, err := resolver.GetSchemaByReference(so.Properties["corners"].Items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corner != so.AnyOf[0] {
+		t.Errorf("expected #/anyOf/0 to resolve to the first anyOf schema")
+	}
+
+	circle, err := resolver.GetSchemaByReference(so.Then.Properties["label"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if circle != so.OneOf[0] {
+		t.Errorf("expected #circle to resolve to the schema with that $id in oneOf")
+	}
+}
+
+const applicatorSchema = `{
+    "$schema": "https://json-schema.org/draft/2020-12/schema",
+    "title": "Inventory",
+    "type": "object",
+    "properties": {
+        "items": { "type": "array", "items": { "$ref": "#/$defs/item" }, "contains": { "$ref": "#/$defs/item" } },
+        "legacy": { "type": "array", "additionalItems": { "type": "string" } }
+    },
+    "$defs": {
+        "item": { "type": "object", "properties": { "sku": { "$ref": "#/patternProperties/^x-/properties/code" } } }
+    },
+    "patternProperties": {
+        "^x-": { "type": "object", "properties": { "code": { "type": "string" } } }
+    },
+    "propertyNames": { "maxLength": 20 },
+    "dependentSchemas": { "legacy": { "required": ["items"] } },
+    "dependencies": { "items": ["legacy"], "legacy": { "$ref": "#/dependentSchemas/legacy" } }
+}`
+
+func TestThatWalkVisitsTheSubschemasOfEveryApplicator(t *testing.T) {
+	so, err := js_inputs.Parse(applicatorSchema, &url.URL{Scheme: "file", Path: "/inventory.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	var pointers []string
+	err = so.Walk(func(pointer string, schema *js_inputs.Schema) error {
+		pointers = append(pointers, pointer)
+		if pointer != "" && schema.Parent == nil {
+			t.Errorf("expected the schema at %s to have a parent", pointer)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the property dependency of "items" is not a schema
+	expected := []string{
+		"",
+		"/$defs/item",
+		"/$defs/item/properties/sku",
+		"/properties/items",
+		"/properties/items/items",
+		"/properties/items/contains",
+		"/properties/legacy",
+		"/properties/legacy/additionalItems",
+		"/patternProperties/^x-",
+		"/patternProperties/^x-/properties/code",
+		"/dependencies/legacy",
+		"/dependentSchemas/legacy",
+		"/propertyNames",
+	}
+	if !reflect.DeepEqual(pointers, expected) {
+		t.Errorf("expected the pointers\n%v\ngot\n%v", expected, pointers)
+	}
+}
+
+func TestThatReferencesIntoEveryApplicatorResolve(t *testing.T) {
+	so, err := js_inputs.Parse(applicatorSchema, &url.URL{Scheme: "file", Path: "/inventory.json"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	resolver := js_inputs.NewRefResolver([]*js_inputs.Schema{so})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		ref      *js_inputs.Schema
+		expected *js_inputs.Schema
+	}{
+		{so.Properties["items"].Items, so.Defs["item"]},
+		{so.Properties["items"].Contains, so.Defs["item"]},
+		{so.Defs["item"].Properties["sku"], so.PatternProperties["^x-"].Properties["code"]},
+		{so.Dependencies["legacy"], so.DependentSchemas["legacy"]},
+	} {
+		resolved, err := resolver.GetSchemaByReference(test.ref)
+		if err != nil {
+			t.Errorf("expected %s to resolve, got %v", test.ref.Reference, err)
+		} else if resolved != test.expected {
+			t.Errorf("expected %s to resolve to the schema at that pointer", test.ref.Reference)
+		}
+	}
+
+	// $defs generate types like definitions
+	g := js_inputs.New(so)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal(err)
+	}
+	if f := g.Structs["Inventory"].Fields["Items"]; f == nil || f.Type.GetTypeAsString() != "[]*Item" {
+		t.Errorf("expected the items to be of the type generated for $defs/item, got %v", f)
+	}
+}
@@ -0,0 +1,116 @@
+package generate
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	js_inputs "github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+)
+
+func TestThatYAMLSchemasCanBeParsed(t *testing.T) {
+	s := `$schema: http://json-schema.org/draft-07/schema#
+title: Order
+type: object
+required: [id]
+properties:
+  id:
+    type: string
+    maxLength: 36
+  total: &money
+    type: number
+    minimum: 0
+  discount: *money
+  200:
+    type: boolean
+    default: yes
+definitions:
+  line:
+    type: object
+    additionalProperties: false
+`
+	uri := &url.URL{Scheme: "file", Path: "/schemas/order.yaml"}
+	so, err := js_inputs.ParseSource("order.yaml", []byte(s), uri, true)
+	if err != nil {
+		t.Fatal("It was not possible to parse the YAML schema:", err)
+	}
+
+	if so.Title != "Order" || so.ID() != uri.String() {
+		t.Errorf("unexpected title %q or id %q", so.Title, so.ID())
+	}
+	if expected := []string{"id", "total", "discount", "200"}; !reflect.DeepEqual(so.PropertyOrder, expected) {
+		t.Errorf("expected the properties in document order %v, got %v", expected, so.PropertyOrder)
+	}
+	if so.Properties["id"].MaxLength == nil || *so.Properties["id"].MaxLength != 36 {
+		t.Errorf("expected maxLength 36, got %v", so.Properties["id"].MaxLength)
+	}
+	if so.Properties["discount"].Minimum == nil || *so.Properties["discount"].Minimum != 0 {
+		t.Error("expected the alias to be expanded")
+	}
+	// YAML 1.2 only knows true and false, so yes is a string
+	if so.Properties["200"].Default != "yes" {
+		t.Errorf("unexpected default %#v", so.Properties["200"].Default)
+	}
+	ap := so.Definitions["line"].AdditionalProperties
+	if ap == nil || ap.AdditionalPropertiesBool == nil || *ap.AdditionalPropertiesBool {
+		t.Error("expected additionalProperties to be false")
+	}
+
+	// a trailing document separator doesn't start another schema
+	if _, err := js_inputs.ParseSource("order.yaml", []byte("---\n"+s+"---\n"), uri, true); err != nil {
+		t.Errorf("expected a trailing separator to be ignored, got %v", err)
+	}
+}
+
+func TestThatYAMLErrorsReportTheirPosition(t *testing.T) {
+	for name, test := range map[string]struct {
+		schema   string
+		expected string
+	}{
+		"syntax": {
+			schema: `$schema: http://json-schema.org/draft-07/schema#
+title: Order
+properties:
+  id: [
+`,
+			expected: "syntax error at order.yaml line 4: did not find expected node content",
+		},
+		"type": {
+			schema: `$schema: http://json-schema.org/draft-07/schema#
+title: Order
+properties:
+  id:
+    type: string
+    maxLength: long
+`,
+			expected: "order.yaml line 6, character 16",
+		},
+		"unsupported": {
+			schema: `$schema: http://json-schema.org/draft-07/schema#
+base: &base
+  type: string
+properties:
+  id:
+    <<: *base
+`,
+			expected: "order.yaml line 6, character 5: merge keys are not supported",
+		},
+		"documents": {
+			schema: `$schema: http://json-schema.org/draft-07/schema#
+title: A
+type: object
+---
+$schema: http://json-schema.org/draft-07/schema#
+title: B
+type: object
+`,
+			expected: "order.yaml line 4, character 1: the file holds more than one YAML document",
+		},
+	} {
+		_, err := js_inputs.ParseSource("order.yaml", []byte(test.schema), &url.URL{Scheme: "file", Path: "/order.yaml"}, true)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	model "github.com/brenank/json-schema-to-go-struct-generator/test/generated/jtd"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go run ../cmd/main.go --input ./samples/jtd --output ./generated/jtd/model.go --package jtd
+
+func TestThatJTDTypesAreMapped(t *testing.T) {
+	e := model.Event{}
+	var _ int32 = e.Sequence
+	var _ time.Time = e.At
+	var _ *string = e.Note
+	var _ float32 = e.Score
+	var _ map[string]string = e.Labels
+	var _ []string = e.Tags
+	var _ uint8 = model.User{}.Age
+	// nullable elements and values are pointers, so null is told apart from the zero value
+	var _ []*string = e.Retries
+	var _ map[string]*int32 = e.Limits
+}
+
+func TestThatJTDNullableElementsRoundTrip(t *testing.T) {
+	e := &model.Event{}
+	payload := []byte(`{"retries":["a",null],"limits":{"cpu":0,"memory":null}}`)
+	if !assert.Nil(t, json.Unmarshal(payload, e)) {
+		return
+	}
+	if assert.Len(t, e.Retries, 2) {
+		assert.Equal(t, "a", *e.Retries[0])
+		assert.Nil(t, e.Retries[1])
+	}
+	if assert.Contains(t, e.Limits, "cpu") && assert.Contains(t, e.Limits, "memory") {
+		assert.Equal(t, int32(0), *e.Limits["cpu"])
+		assert.Nil(t, e.Limits["memory"])
+	}
+}
+
+func TestThatJTDDiscriminatorsRoundTrip(t *testing.T) {
+	payload := []byte(`{"kind":"signed_up","user":{"id":"u-1","age":30,"nickname":null}}`)
+	p := &model.Payload{}
+	if !assert.Nil(t, json.Unmarshal(payload, p)) {
+		return
+	}
+	assert.Equal(t, "signed_up", p.Kind)
+	assert.Nil(t, p.Renamed)
+	if assert.NotNil(t, p.SignedUp) {
+		assert.Equal(t, "u-1", p.SignedUp.User.Id)
+		assert.Nil(t, p.SignedUp.User.Nickname)
+	}
+	assert.Nil(t, p.Validate())
+
+	b, err := json.Marshal(p)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(payload), string(b))
+
+	b, err = json.Marshal(&model.Payload{Kind: "renamed", Renamed: &model.PayloadRenamed{From: "a", To: "b"}})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"kind":"renamed","from":"a","to":"b"}`, string(b))
+}
+
+func TestThatJTDDiscriminatorsAreChecked(t *testing.T) {
+	p := &model.Payload{}
+	assert.EqualError(t, json.Unmarshal([]byte(`{"kind":"deleted"}`), p), `unknown kind "deleted"`)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"user":{}}`), p), "the kind of Payload is missing")
+
+	// the variant reads the properties besides the tag, so its own checks apply
+	assert.EqualError(t, json.Unmarshal([]byte(`{"kind":"signed_up","other":1}`), p), `additional property not allowed: "other"`)
+	assert.Nil(t, json.Unmarshal([]byte(`{"kind":"signed_up"}`), p))
+	assert.NotNil(t, p.Validate())
+	// unless it allows additional properties
+	assert.Nil(t, json.Unmarshal([]byte(`{"kind":"renamed","from":"a","to":"b","by":"c"}`), p))
+
+	_, err := json.Marshal(&model.Payload{Kind: "deleted"})
+	assert.Error(t, err)
+}
+
+func TestThatJTDVariantsMustHaveDistinctNames(t *testing.T) {
+	fsys := fstest.MapFS{"shape.jtd.json": {Data: []byte(`{
+        "discriminator": "type",
+        "mapping": {
+            "type": { "properties": {} }
+        }
+    }`)}}
+
+	_, err := generateFromFS(fsys, converter.Options{}, "shape.jtd.json")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `the variant "type" of`)
+	}
+}
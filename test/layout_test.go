@@ -0,0 +1,24 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	layout "github.com/brenank/json-schema-to-go-struct-generator/test/generated/layout"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go run ../cmd/main.go --input ./samples/marshal --output ./generated/layout/model.go --package layout --layout type
+
+func TestThatTypesCanBeGeneratedIntoTheirOwnFiles(t *testing.T) {
+	for _, name := range []string{"address.go", "example.go", "status.go", "schema_helpers.go"} {
+		_, err := os.Stat("./generated/layout/" + name)
+		assert.Nil(t, err)
+	}
+
+	e := &layout.Example{}
+	err := json.Unmarshal([]byte(`{"name":"nameValue","address":{"county":"countyValue"}}`), e)
+	assert.Nil(t, err)
+	assert.Equal(t, "countyValue", e.Address.County)
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+	"github.com/stretchr/testify/assert"
+)
+
+const libraryOrderSchema = `{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "https://schemas.example.com/order.json",
+    "title": "Order",
+    "type": "object",
+    "properties": {
+        "id": { "type": "string" },
+        "customer": { "$ref": "customer.json" }
+    }
+}`
+
+const libraryCustomerSchema = `{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "$id": "https://schemas.example.com/customer.json",
+    "title": "Customer",
+    "type": "object",
+    "properties": { "name": { "type": "string" } }
+}`
+
+func librarySources(t *testing.T) []inputs.Source {
+	customer, err := inputs.NewSource("https://schemas.example.com/customer.json", strings.NewReader(libraryCustomerSchema))
+	assert.Nil(t, err)
+	return []inputs.Source{
+		{URI: "https://schemas.example.com/order.json", Content: []byte(libraryOrderSchema)},
+		customer,
+	}
+}
+
+func TestThatSourceCanBeGeneratedInMemory(t *testing.T) {
+	src, err := converter.GenerateSource(librarySources(t), converter.Options{PackageName: "orders"})
+	assert.Nil(t, err)
+
+	code := string(src)
+	assert.True(t, strings.Contains(code, "package orders"), code)
+	assert.True(t, strings.Contains(code, "// Source paths:  https://schemas.example.com/order.json:https://schemas.example.com/customer.json"), code)
+	assert.True(t, strings.Contains(code, "Customer *Customer `json:\"customer,omitempty\"`"), code)
+}
+
+func TestThatGeneratedFilesCanBeReturnedInMemory(t *testing.T) {
+	opts := converter.Options{PackageName: "orders", Output: inputs.OutputOptions{Layout: inputs.LayoutPerType}}
+	pkgs, err := converter.Generate(librarySources(t), opts)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(pkgs)) {
+		var names []string
+		for _, f := range pkgs[0].Files {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"customer.go", "order.go"}, names)
+	}
+
+	_, err = converter.GenerateSource(librarySources(t), opts)
+	assert.NotNil(t, err)
+}
+
+func TestThatInvalidSourcesAreReportedByURI(t *testing.T) {
+	_, err := converter.GenerateSource([]inputs.Source{{URI: "https://schemas.example.com/bad.json", Content: []byte("{\n  \"title\": ,\n  \"type\": \"object\"\n}")}}, converter.Options{PackageName: "bad"})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "syntax error at https://schemas.example.com/bad.json line"), err.Error())
+	}
+}
+
+func TestThatPackagesAreWrittenAtomically(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "model.go")
+	assert.Nil(t, os.WriteFile(out, []byte("previous"), 0644))
+
+	err := converter.WritePackages([]inputs.GeneratedPackage{{
+		Name:  "models",
+		Dir:   dir,
+		Files: []inputs.File{{Name: "model.go", Content: []byte("package models\n")}},
+	}})
+	assert.Nil(t, err)
+
+	b, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Equal(t, "package models\n", string(b))
+
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries), "expected no temporary files to be left behind")
+}
+
+func TestThatAFailedConversionLeavesTheOutputUntouched(t *testing.T) {
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "broken.json")
+	assert.Nil(t, os.WriteFile(schema, []byte(`{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Broken",
+        "type": "object",
+        "properties": { "lines": { "type": "array", "x-go-type": "[]Line{" } }
+    }`), 0644))
+	out := filepath.Join(dir, "out", "model.go")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(out), 0755))
+	assert.Nil(t, os.WriteFile(out, []byte("previous"), 0644))
+
+	err := converter.ConvertWithOptions([]string{schema}, out, converter.Options{PackageName: "broken"})
+	assert.NotNil(t, err)
+
+	b, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Equal(t, "previous", string(b))
+}
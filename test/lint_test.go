@@ -0,0 +1,212 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+	"github.com/stretchr/testify/assert"
+)
+
+func lintText(t *testing.T, fsys fstest.MapFS, files ...string) string {
+	findings, err := inputs.LintFS(fsys, files, inputs.GeneratorOptions{})
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	var b bytes.Buffer
+	assert.Nil(t, inputs.WriteLintText(&b, findings))
+	return b.String()
+}
+
+func TestThatLintReportsWhatTheGeneratorPapersOver(t *testing.T) {
+	fsys := fstest.MapFS{"order.json": {Data: []byte(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["id", "total"],
+  "properties": {
+    "id": { "type": "integer", "format": "uuid" },
+    "line-items": { "type": "array", "items": { "$ref": "#/definitions/item" }, "minItems": 1 },
+    "notes": { "type": "string" },
+    "amount": { "type": "integer", "format": "double" }
+  },
+  "definitions": {
+    "item": { "type": "object", "title": "Item", "properties": { "sku": { "type": "string" } } },
+    "legacy": { "type": "string" }
+  }
+}`)}}
+
+	assert.Equal(t, `order.json:1:1: warning: the object has no title, so its type is named Root; add a title or x-go-name (#) [anonymous-type]
+order.json:4:22: error: the required property "total" is not defined in properties (#/required/1) [undefined-required]
+order.json:6:42: warning: the format "uuid" applies to string values, not integer (#/properties/id/format) [type-format-mismatch]
+order.json:7:93: warning: the keyword "minItems" has no effect on the generated code (#/properties/line-items/minItems) [ignored-keyword]
+order.json:13:15: warning: the definition "legacy" is not referenced (#/definitions/legacy) [unused-definition]
+`, lintText(t, fsys, "order.json"))
+}
+
+func TestThatLintAcceptsSchemasWithoutProblems(t *testing.T) {
+	fsys := fstest.MapFS{
+		"order.json": {Data: []byte(`{
+  "title": "Order",
+  "type": "object",
+  "required": ["id", "customer"],
+  "allOf": [{ "properties": { "customer": { "$ref": "common.json#/definitions/name" } } }],
+  "properties": {
+    "id": { "type": ["string", "null"], "format": "uuid" },
+    "count": { "type": "integer", "format": "int64" },
+    "price": { "type": "integer", "format": "double" }
+  }
+}`)},
+		// definitions referenced by other documents are used
+		"common.json": {Data: []byte(`{ "definitions": { "name": { "type": "string" } } }`)},
+	}
+
+	assert.Equal(t, "", lintText(t, fsys, "order.json", "common.json"))
+}
+
+func TestThatLintReportsNameCollisions(t *testing.T) {
+	fsys := fstest.MapFS{"bag.yaml": {Data: []byte(`title: Bag
+type: object
+properties:
+  line-items:
+    type: string
+  line_items:
+    type: string
+  additionalProperties:
+    type: string
+additionalProperties: true
+`)}}
+
+	// the types of objects with colliding fields can't be created, so only the collisions are reported
+	assert.Equal(t, `bag.yaml:7:5: error: the properties "line-items" and "line_items" both become the field LineItems (#/properties/line_items) [name-collision]
+bag.yaml:9:5: error: the property "additionalProperties" becomes the field AdditionalProperties, which holds the additional properties (#/properties/additionalProperties) [name-collision]
+`, lintText(t, fsys, "bag.yaml"))
+}
+
+func TestThatLintReportsMetaSchemaViolations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad.json":  {Data: []byte(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Bad", "type": "strng" }`)},
+		"good.json": {Data: []byte(`{ "title": "Good", "type": "string" }`)},
+	}
+
+	findings, err := inputs.LintFS(fsys, []string{"bad.json", "good.json"}, inputs.GeneratorOptions{})
+	if assert.Nil(t, err) && assert.Len(t, findings, 1) {
+		assert.Equal(t, "meta-schema", findings[0].Rule)
+		assert.Equal(t, inputs.LevelError, findings[0].Level)
+		assert.Equal(t, inputs.Pointer{"type"}, findings[0].Pointer)
+		assert.Equal(t, 1, findings[0].Line)
+	}
+}
+
+func TestThatLintFindingsAreWrittenAsSARIF(t *testing.T) {
+	fsys := fstest.MapFS{"schemas/user.json": {Data: []byte(`{
+  "title": "User",
+  "type": "object",
+  "required": ["email"]
+}`)}}
+
+	findings, err := inputs.LintFS(fsys, []string{"schemas/user.json"}, inputs.GeneratorOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	var b bytes.Buffer
+	if !assert.Nil(t, inputs.WriteSARIF(&b, findings)) {
+		return
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				RuleIndex int
+				Level     string
+				Message   struct{ Text string }
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn int }
+					}
+				}
+			}
+		}
+	}
+	if !assert.Nil(t, json.Unmarshal(b.Bytes(), &log)) || !assert.Len(t, log.Runs, 1) {
+		return
+	}
+	run := log.Runs[0]
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Equal(t, "schema-generate", run.Tool.Driver.Name)
+	assert.Len(t, run.Tool.Driver.Rules, len(inputs.LintRules))
+	if assert.Len(t, run.Results, 1) {
+		r := run.Results[0]
+		assert.Equal(t, "undefined-required", r.RuleID)
+		assert.Equal(t, "undefined-required", run.Tool.Driver.Rules[r.RuleIndex].ID)
+		assert.Equal(t, "error", r.Level)
+		assert.Equal(t, `the required property "email" is not defined in properties`, r.Message.Text)
+		if assert.Len(t, r.Locations, 1) {
+			assert.Equal(t, "schemas/user.json", r.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+			assert.Equal(t, 4, r.Locations[0].PhysicalLocation.Region.StartLine)
+			assert.Equal(t, 16, r.Locations[0].PhysicalLocation.Region.StartColumn)
+		}
+	}
+}
+
+func TestThatLintReportsDefinitionsWhichBecomeTheSameType(t *testing.T) {
+	fsys := fstest.MapFS{"order.json": {Data: []byte(`{
+  "title": "Order",
+  "type": "object",
+  "properties": {
+    "billing": { "$ref": "#/definitions/postal-address" },
+    "shipping": { "$ref": "#/definitions/postalAddress" },
+    "home": { "$ref": "#/$defs/home" }
+  },
+  "definitions": {
+    "postal-address": { "type": "object", "properties": { "street": { "type": "string" } } },
+    "postalAddress": { "type": "object", "properties": { "line": { "type": "string" } } }
+  },
+  "$defs": {
+    "home": { "title": "Postal Address", "type": "object", "properties": { "city": { "type": "string" } } }
+  }
+}`)}}
+
+	assert.Equal(t, `order.json:11:22: warning: the definitions "postal-address" and "postalAddress" both become the type PostalAddress; add a title or x-go-name to tell them apart (#/definitions/postalAddress) [type-name-collision]
+order.json:14:13: warning: the definitions "postal-address" and "home" both become the type PostalAddress; add a title or x-go-name to tell them apart (#/$defs/home) [type-name-collision]
+`, lintText(t, fsys, "order.json"))
+}
+
+func TestThatLintReportsReferencesWhichCannotBeResolved(t *testing.T) {
+	fsys := fstest.MapFS{"order.json": {Data: []byte(`{
+  "title": "Order",
+  "type": "object",
+  "properties": {
+    "customer": { "$ref": "#/definitions/missing" },
+    "address": { "$ref": "common.json#/definitions/address" }
+  }
+}`)}}
+
+	assert.Equal(t, `order.json:5:27: error: the reference "#/definitions/missing" doesn't identify a schema (#/properties/customer/$ref) [unresolved-reference]
+order.json:6:26: error: the reference "common.json#/definitions/address" can't be resolved: file:///common.json was not found, tried /common.json (#/properties/address/$ref) [unresolved-reference]
+`, lintText(t, fsys, "order.json"))
+}
+
+func TestThatLintReportsGeneratorErrors(t *testing.T) {
+	fsys := fstest.MapFS{"order.json": {Data: []byte(`{
+  "title": "Order",
+  "type": "object",
+  "properties": {
+    "id": { "type": "string", "x-go-tags": { "json": "identifier" } }
+  }
+}`)}}
+
+	// the error is reported against the schema it names
+	assert.Equal(t, `order.json:5:11: error: x-go-tags at "file:///order.json#/properties/id" can't set the json tag, which is the property name; set x-go-name to rename the field (#/properties/id) [generator-error]
+`, lintText(t, fsys, "order.json"))
+}
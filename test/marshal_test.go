@@ -11,7 +11,7 @@ import (
 //go:generate go run ../cmd/main.go --input ./samples/marshal --output ./generated/marshal/model.go
 
 func TestThatJSONCanBeRoundtrippedUsingGeneratedStructs(t *testing.T) {
-	j := `{"address":{"county":"countyValue"},"name":"nameValue"}`
+	j := `{"name":"nameValue","address":{"county":"countyValue"}}`
 
 	e := &model.Example{}
 	err := json.Unmarshal([]byte(j), e)
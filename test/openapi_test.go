@@ -0,0 +1,230 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+	model "github.com/brenank/json-schema-to-go-struct-generator/test/generated/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go run ../cmd/main.go --input ./samples/openapi --output ./generated/openapi/model.go --package openapi --typecheck
+
+const petstore = `openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    parameters:
+      - name: limit
+        in: query
+        schema: { type: integer }
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: the pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: { $ref: "#/components/schemas/Pet" }
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: { type: string }
+                tag: { type: string }
+      responses:
+        "201":
+          content:
+            application/json:
+              schema: { $ref: "#/components/schemas/Pet" }
+        default:
+          $ref: "#/components/responses/Problem"
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name, kind]
+      properties:
+        id: { type: integer, readOnly: true }
+        name: { type: string }
+        tag: { type: string, nullable: true }
+        kind: { $ref: "#/components/schemas/Kind" }
+    Kind:
+      oneOf:
+        - $ref: "#/components/schemas/Cat"
+        - $ref: "#/components/schemas/Dog"
+      discriminator:
+        propertyName: type
+        mapping:
+          cat: Cat
+    Cat:
+      type: object
+      properties:
+        type: { type: string }
+        indoor: { type: boolean }
+    Dog:
+      type: object
+      properties:
+        type: { type: string }
+        good: { type: boolean }
+  responses:
+    Problem:
+      content:
+        application/problem+json:
+          schema:
+            type: object
+            properties:
+              detail: { type: string }
+`
+
+func TestThatOpenAPIComponentsAreGenerated(t *testing.T) {
+	code, err := generateFromFS(fstest.MapFS{"api/petstore.yaml": {Data: []byte(petstore)}}, converter.Options{}, "api/petstore.yaml")
+	assert.Nil(t, err)
+	for _, expected := range []string{
+		"type Pet struct {",
+		"Id int `json:\"id,omitempty\"`",
+		"Name string `json:\"name\"`",
+		"Tag *string `json:\"tag,omitempty\"`",
+		"type Cat struct {",
+		"type Dog struct {",
+	} {
+		assert.Contains(t, code, expected)
+	}
+	// the document itself has no type, and bodies are only generated when asked to
+	for _, unexpected := range []string{"Petstore", "Root", "CreatePetRequest", "Problem"} {
+		assert.NotContains(t, code, unexpected)
+	}
+}
+
+func TestThatOpenAPIBodiesAreGeneratedWhenEnabled(t *testing.T) {
+	code, err := generateFromFS(fstest.MapFS{"api/petstore.yaml": {Data: []byte(petstore)}},
+		converter.Options{Generator: inputs.GeneratorOptions{OpenAPIBodies: true}}, "api/petstore.yaml")
+	assert.Nil(t, err)
+	for _, expected := range []string{
+		"type CreatePetRequest struct {",
+		"Name string `json:\"name\"`",
+		"type ListPets200Response []*Pet",
+		"type Problem struct {",
+		"Detail string `json:\"detail,omitempty\"`",
+	} {
+		assert.Contains(t, code, expected)
+	}
+	// a body which refers to a component uses its type
+	assert.NotContains(t, code, "CreatePet201Response")
+}
+
+func TestThatSchemasCanReferToOpenAPIComponents(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/petstore.yaml": {Data: []byte(petstore)},
+		"schemas/adoption.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Adoption",
+            "type": "object",
+            "properties": {
+                "pet": { "$ref": "../api/petstore.yaml#/components/schemas/Pet" }
+            }
+        }`)},
+	}
+
+	code, err := generateFromFS(fsys, converter.Options{}, "schemas/adoption.json")
+	assert.Nil(t, err)
+	assert.Contains(t, code, "Pet *Pet `json:\"pet,omitempty\"`")
+	assert.Contains(t, code, "type Pet struct {")
+}
+
+func TestThatDiscriminatorMappingsMustResolve(t *testing.T) {
+	fsys := fstest.MapFS{"api.json": {Data: []byte(`{
+        "openapi": "3.1.0",
+        "info": { "title": "Pets", "version": "1" },
+        "components": {
+            "schemas": {
+                "Pet": {
+                    "oneOf": [ { "$ref": "#/components/schemas/Cat" } ],
+                    "discriminator": { "propertyName": "type", "mapping": { "dog": "Dog" } }
+                },
+                "Cat": { "type": "object", "properties": { "type": { "type": "string" } } }
+            }
+        }
+    }`)}}
+
+	_, err := generateFromFS(fsys, converter.Options{}, "api.json")
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `discriminator mapping "dog" at "file:///api.json#/components/schemas/Pet": reference "#/components/schemas/Dog" not found`)
+	}
+}
+
+func TestThatComponentsMayDeclareTheirDialect(t *testing.T) {
+	document := `openapi: 3.1.0
+info: { title: Petstore, version: 1.0.0 }
+paths: {}
+components:
+  schemas:
+    Pet:
+      $schema: https://json-schema.org/draft/2020-12/schema
+      type: object
+      properties:
+        name: { type: string }
+`
+	code, err := generateFromFS(fstest.MapFS{"api.yaml": {Data: []byte(document)}}, converter.Options{}, "api.yaml")
+	assert.Nil(t, err)
+	assert.Contains(t, code, "type Pet struct { Name string `json:\"name,omitempty\"` }")
+}
+
+func TestThatOpenAPIComponentsWhichAreNotObjectsAreDeclared(t *testing.T) {
+	document := `openapi: 3.1.0
+info: { title: Pets, version: 1.0.0 }
+paths: {}
+components:
+  schemas:
+    PetName:
+      type: string
+    PetList:
+      type: array
+      items: { $ref: "#/components/schemas/Pet" }
+    Pet:
+      type: object
+      properties:
+        name: { $ref: "#/components/schemas/PetName" }
+`
+	code, err := generateFromFS(fstest.MapFS{"pets.yaml": {Data: []byte(document)}}, converter.Options{TypeCheck: true}, "pets.yaml")
+	assert.Nil(t, err)
+	for _, expected := range []string{
+		"type PetList []*Pet",
+		"type PetName string",
+		"type Pet struct {",
+	} {
+		assert.Contains(t, code, expected)
+	}
+}
+
+func TestThatDiscriminatedComponentsAreUnions(t *testing.T) {
+	var pets model.PetList
+	err := json.Unmarshal([]byte(`[{"petType":"cat","name":"Tom","indoor":true},{"petType":"dog","name":"Rex"}]`), &pets)
+	if !assert.Nil(t, err) || !assert.Len(t, pets, 2) {
+		return
+	}
+	// the variants read the discriminator property too
+	assert.Equal(t, &model.Pet{PetType: "cat", Cat: &model.Cat{PetType: "cat", Name: "Tom", Indoor: true}}, pets[0])
+	assert.Equal(t, "Rex", pets[1].Dog.Name)
+	assert.Nil(t, pets[1].Validate())
+
+	// the tag of the union is written once, whatever the variant holds
+	b, err := json.Marshal(&model.Pet{PetType: "dog", Dog: &model.Dog{Name: "Rex", Good: true}})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"petType":"dog","name":"Rex","good":true}`, string(b))
+
+	var name model.PetName = "Tom"
+	assert.Equal(t, "Tom", string(name))
+}
@@ -0,0 +1,25 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/test/generated/packages/common"
+	sales "github.com/brenank/json-schema-to-go-struct-generator/test/generated/packages/sales"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go run ../cmd/main.go --input ./samples/packages --output ./generated/packages/sales/model.go --package sales --packages https://schemas.example.com/common/=github.com/brenank/json-schema-to-go-struct-generator/test/generated/packages/common:./generated/packages/common
+
+func TestThatSchemasAreGeneratedIntoTheirPackages(t *testing.T) {
+	j := `{"name":"alice","address":{"street":"1 High St"},"previousAddresses":[{"street":"2 Low St","town":"Leeds"}]}`
+
+	c := &sales.Customer{}
+	err := json.Unmarshal([]byte(j), c)
+	assert.Nil(t, err)
+
+	var address *common.Address = c.Address
+	assert.Equal(t, "1 High St", address.Street)
+	assert.Nil(t, address.Validate())
+	assert.Equal(t, "Leeds", c.PreviousAddresses[0].Town)
+}
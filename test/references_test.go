@@ -0,0 +1,260 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+	"github.com/stretchr/testify/assert"
+)
+
+const addressSchema = `{
+    "$schema": "http://json-schema.org/draft-07/schema#",
+    "title": "Address",
+    "type": "object",
+    "properties": { "town": { "type": "string" } },
+    "definitions": {
+        "street": { "type": "object", "properties": { "name": { "type": "string" } } }
+    }
+}`
+
+// generateFromFS returns the generated code with runs of whitespace collapsed, so fields match regardless of their
+// alignment.
+func generateFromFS(fsys fstest.MapFS, opts converter.Options, files ...string) (string, error) {
+	opts.PackageName = "orders"
+	pkgs, err := converter.GenerateFS(fsys, files, opts)
+	if err != nil {
+		return "", err
+	}
+	var code strings.Builder
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			code.Write(f.Content)
+		}
+	}
+	return strings.Join(strings.Fields(code.String()), " "), nil
+}
+
+func TestThatReferencedSchemasAreLoadedOnDemand(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/orders/order.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Order",
+            "type": "object",
+            "properties": {
+                "delivery": { "$ref": "../common/address.json" },
+                "street": { "$ref": "../common/address.json#/definitions/street" }
+            }
+        }`)},
+		"schemas/common/address.json": {Data: []byte(addressSchema)},
+	}
+
+	code, err := generateFromFS(fsys, converter.Options{}, "schemas/orders/order.json")
+	assert.Nil(t, err)
+	for _, expected := range []string{
+		"type Order struct",
+		"Delivery *Address `json:\"delivery,omitempty\"`",
+		"Street *Street `json:\"street,omitempty\"`",
+		"type Address struct",
+		"type Street struct",
+	} {
+		assert.True(t, strings.Contains(code, expected), "expected %q in:\n%s", expected, code)
+	}
+}
+
+func TestThatReferencedSchemasAreFoundOnSearchPaths(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/order.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "$id": "https://schemas.example.com/orders/order.json",
+            "title": "Order",
+            "type": "object",
+            "properties": {
+                "delivery": { "$ref": "common/address.json" },
+                "billing": { "$ref": "https://schemas.example.com/shared/address.json#/definitions/street" }
+            }
+        }`)},
+		"shared/common/address.json": {Data: []byte(addressSchema)},
+		"shared/address.json":        {Data: []byte(addressSchema)},
+	}
+
+	_, err := generateFromFS(fsys, converter.Options{}, "schemas/order.json")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "tried /schemas/common/address.json"), err.Error())
+	}
+
+	code, err := generateFromFS(fsys, converter.Options{
+		Generator: inputs.GeneratorOptions{SearchPaths: []string{"shared"}},
+	}, "schemas/order.json")
+	assert.Nil(t, err)
+	for _, expected := range []string{
+		"Delivery *Address `json:\"delivery,omitempty\"`",
+		"Billing *Street `json:\"billing,omitempty\"`",
+	} {
+		assert.True(t, strings.Contains(code, expected), "expected %q in:\n%s", expected, code)
+	}
+}
+
+func TestThatDocumentsReferringToEachOtherAreLoadedOnce(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/person.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Person",
+            "type": "object",
+            "properties": { "employer": { "$ref": "company.json" } }
+        }`)},
+		"schemas/company.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Company",
+            "type": "object",
+            "properties": { "owner": { "$ref": "person.json" } }
+        }`)},
+	}
+
+	code, err := generateFromFS(fsys, converter.Options{}, "schemas/person.json")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(code, "type Person struct"), code)
+	assert.Equal(t, 1, strings.Count(code, "type Company struct"), code)
+	assert.True(t, strings.Contains(code, "Owner *Person `json:\"owner,omitempty\"`"), code)
+}
+
+func TestThatCircularReferencesAreReported(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/list.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "List",
+            "type": "object",
+            "properties": { "items": { "$ref": "nested.json#/definitions/nested" } }
+        }`)},
+		"schemas/nested.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "definitions": {
+                "nested": { "type": "array", "items": { "$ref": "#/definitions/nested" } }
+            }
+        }`)},
+	}
+
+	_, err := generateFromFS(fsys, converter.Options{}, "schemas/list.json")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(),
+			"circular reference file:///schemas/nested.json#/definitions/nested -> file:///schemas/nested.json#/definitions/nested"),
+			err.Error())
+	}
+}
+
+func TestThatReferencedSchemasAreLoadedFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"orders/order.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Order",
+            "type": "object",
+            "properties": {
+                "delivery": { "$ref": "../common/address.json" },
+                "street": { "$ref": "street.json" }
+            }
+        }`,
+		"common/address.json": addressSchema,
+		"refs/street.json": `{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Street",
+            "type": "object",
+            "properties": { "name": { "type": "string" } }
+        }`,
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	output := filepath.Join(dir, "out", "orders.go")
+	err := converter.ConvertWithOptions([]string{filepath.Join(dir, "orders", "order.json")}, output, converter.Options{
+		PackageName: "orders",
+		Generator:   inputs.GeneratorOptions{SearchPaths: []string{filepath.Join(dir, "refs")}},
+	})
+	assert.Nil(t, err)
+	code, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	for _, expected := range []string{"type Address struct", "type Street struct"} {
+		assert.True(t, strings.Contains(string(code), expected), "expected %q in:\n%s", expected, code)
+	}
+}
+
+func TestThatRemoteReferencesAreLoadedByTheLoaders(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/common/address.json":
+			w.Write([]byte(`{
+                "$schema": "http://json-schema.org/draft-07/schema#",
+                "title": "Address",
+                "type": "object",
+                "properties": { "street": { "$ref": "street.json" } }
+            }`))
+		case "/common/street.json":
+			w.Write([]byte(`{
+                "$schema": "http://json-schema.org/draft-07/schema#",
+                "title": "Street",
+                "type": "object",
+                "properties": { "name": { "type": "string" } }
+            }`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	order := inputs.Source{URI: "file:///schemas/order.json", Content: []byte(`{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Order",
+        "type": "object",
+        "properties": {
+            "delivery": { "$ref": "` + server.URL + `/common/address.json" },
+            "customer": { "$ref": "https://schemas.example.com/customer.json" }
+        }
+    }`)}
+	mapped := &inputs.PrefixLoader{
+		Prefixes: map[string]string{"https://schemas.example.com/": "vendor"},
+		FS: fstest.MapFS{"vendor/customer.json": {Data: []byte(`{
+            "$schema": "http://json-schema.org/draft-07/schema#",
+            "title": "Customer",
+            "type": "object",
+            "properties": { "email": { "type": "string" } }
+        }`)}},
+	}
+	cache := t.TempDir()
+	generate := func(loaders ...inputs.Loader) (string, error) {
+		code, err := converter.GenerateSource([]inputs.Source{order}, converter.Options{
+			PackageName: "orders",
+			Generator:   inputs.GeneratorOptions{Loaders: loaders},
+		})
+		return string(code), err
+	}
+
+	// nothing is fetched by default
+	_, err := generate(mapped)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "common/address.json"), err.Error())
+	}
+	assert.Equal(t, 0, requests)
+
+	code, err := generate(mapped, &inputs.CacheLoader{Dir: cache, Next: &inputs.HTTPLoader{Client: server.Client()}})
+	assert.Nil(t, err)
+	for _, expected := range []string{"type Address struct", "type Street struct", "type Customer struct"} {
+		assert.True(t, strings.Contains(code, expected), "expected %q in:\n%s", expected, code)
+	}
+	assert.Equal(t, 2, requests)
+
+	// the cache populated above is enough without the server
+	server.Close()
+	cached, err := generate(mapped, &inputs.CacheLoader{Dir: cache})
+	assert.Nil(t, err)
+	assert.Equal(t, code, cached)
+}
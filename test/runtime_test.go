@@ -0,0 +1,78 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/schemaruntime"
+	runtime "github.com/brenank/json-schema-to-go-struct-generator/test/generated/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go run ../cmd/main.go --input ./samples/runtime --output ./generated/runtime/model.go --package runtime --runtime
+
+func TestThatRuntimeCodeUnmarshalsAndValidates(t *testing.T) {
+	o := &runtime.Order{}
+	err := json.Unmarshal([]byte(`{"quantity":2,"delivery":{"street":"1 High St"},"note":"leave at door"}`), o)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, o.Quantity)
+	assert.Equal(t, "1 High St", o.Delivery.Street)
+	assert.Equal(t, map[string]string{"note": "leave at door"}, o.AdditionalProperties)
+	assert.Nil(t, o.Delivery.Validate())
+
+	errs := o.Validate()
+	assert.Equal(t, 1, len(errs))
+	assert.ErrorIs(t, errs[0], runtime.ErrFieldRequired)
+	assert.ErrorIs(t, errs[0], schemaruntime.ErrFieldRequired)
+
+	// unmarshalling a complete order again clears the error
+	err = json.Unmarshal([]byte(`{"id":"a1","quantity":2}`), o)
+	assert.Nil(t, err)
+	assert.Nil(t, o.Validate())
+}
+
+func TestThatRuntimeCodeRejectsAdditionalProperties(t *testing.T) {
+	d := &runtime.Delivery{}
+	err := json.Unmarshal([]byte(`{"street":"1 High St","town":"Leeds"}`), d)
+	assert.EqualError(t, err, `additional property not allowed: "town"`)
+}
+
+func TestThatRuntimeCodeMarshalsAdditionalPropertiesInOrder(t *testing.T) {
+	o := &runtime.Order{
+		Id:                   "a1",
+		Quantity:             2,
+		AdditionalProperties: map[string]string{"zeta": "z", "alpha": "a"},
+	}
+	b, err := json.Marshal(o)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"a1","quantity":2,"delivery":null,"alpha":"a","zeta":"z"}`, string(b))
+}
+
+func TestThatRuntimeCodeLeavesOutEmptyOmitEmptyFields(t *testing.T) {
+	b, err := json.Marshal(&runtime.Order{Id: "a1", Quantity: 2, Tags: []string{}})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"a1","quantity":2,"delivery":null}`, string(b))
+
+	b, err = json.Marshal(&runtime.Order{Id: "a1", Quantity: 2, Tags: []string{"gift"}})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"a1","quantity":2,"tags":["gift"],"delivery":null}`, string(b))
+}
+
+func TestThatRuntimeCodeOnlyImportsTheRuntime(t *testing.T) {
+	b, err := os.ReadFile("./generated/runtime/model.go")
+	assert.Nil(t, err)
+	for _, pkg := range []string{`"bytes"`, `"errors"`, `"fmt"`} {
+		assert.False(t, strings.Contains(string(b), pkg), "unexpected import %s", pkg)
+	}
+}
+
+func TestThatRuntimeErrorsSkipNil(t *testing.T) {
+	missing := schemaruntime.RequiredError("Id")
+	assert.Nil(t, schemaruntime.Errors(nil, nil))
+	assert.Equal(t, []error{missing}, schemaruntime.Errors(nil, missing))
+	assert.True(t, errors.Is(missing, schemaruntime.ErrFieldRequired))
+}
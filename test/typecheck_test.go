@@ -0,0 +1,46 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/inputs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThatConvertTypeChecksTheRuntimeOutput(t *testing.T) {
+	out := "./generated/typecheck/runtime/model.go"
+	err := converter.ConvertWithOptions([]string{"./samples/runtime/order.json"}, out, converter.Options{
+		PackageName: "runtime",
+		Output:      inputs.OutputOptions{Runtime: true},
+		TypeCheck:   true,
+	})
+	assert.Nil(t, err)
+
+	_, err = os.Stat(out)
+	assert.Nil(t, err)
+}
+
+func TestThatConvertDoesNotWriteCodeWhichDoesNotCompile(t *testing.T) {
+	schema := filepath.Join(t.TempDir(), "broken.json")
+	err := os.WriteFile(schema, []byte(`{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": "Broken",
+        "type": "object",
+        "properties": { "when": { "type": "string", "x-go-type": "time.Time" } }
+    }`), 0644)
+	assert.Nil(t, err)
+
+	out := "./generated/typecheck/broken/model.go"
+	err = converter.ConvertWithOptions([]string{schema}, out, converter.Options{PackageName: "broken", TypeCheck: true})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "undefined: time"), err.Error())
+		assert.True(t, strings.Contains(err.Error(), "type Broken generated from file://"), err.Error())
+	}
+
+	_, err = os.Stat(out)
+	assert.True(t, os.IsNotExist(err))
+}
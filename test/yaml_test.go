@@ -0,0 +1,54 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/brenank/json-schema-to-go-struct-generator/pkg/converter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThatYAMLSchemasAndReferencesAreGenerated(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/order.yaml": {Data: []byte(`$schema: http://json-schema.org/draft-07/schema#
+title: Order
+type: object
+required: [id]
+properties:
+  id:
+    type: string
+  delivery:
+    $ref: common/address.yml
+  street:
+    $ref: common/address.yml#/definitions/street
+`)},
+		"schemas/common/address.yml": {Data: []byte(`$schema: http://json-schema.org/draft-07/schema#
+title: Address
+type: object
+properties:
+  town:
+    type: string
+definitions:
+  street:
+    type: object
+    properties:
+      name:
+        type: string
+      number:
+        type: integer
+`)},
+	}
+
+	code, err := generateFromFS(fsys, converter.Options{}, "schemas/order.yaml")
+	assert.Nil(t, err)
+	for _, expected := range []string{
+		"Id string `json:\"id\"`",
+		"Delivery *Address `json:\"delivery,omitempty\"`",
+		"Street *Street `json:\"street,omitempty\"`",
+		"type Address struct",
+		"type Street struct { Name string `json:\"name,omitempty\"` Number int `json:\"number,omitempty\"` }",
+	} {
+		assert.True(t, strings.Contains(code, expected), "expected %q in:\n%s", expected, code)
+	}
+}